@@ -0,0 +1,425 @@
+package gogetter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type associationKind int
+
+const (
+	belongsTo associationKind = iota
+	hasOne
+	hasMany
+)
+
+type association struct {
+	kind       associationKind
+	name       string
+	goal       string
+	foreignKey string
+	count      int
+}
+
+// See (r *Registry) BelongsTo.
+func BelongsTo(name, assocName, goal, foreignKey string) {
+	defaultRegistry.BelongsTo(name, assocName, goal, foreignKey)
+}
+
+// See (r *Registry) HasOne.
+func HasOne(name, assocName, goal, foreignKey string) {
+	defaultRegistry.HasOne(name, assocName, goal, foreignKey)
+}
+
+// See (r *Registry) HasMany.
+func HasMany(name, assocName, goal, foreignKey string, count int) {
+	defaultRegistry.HasMany(name, assocName, goal, foreignKey, count)
+}
+
+// BelongsTo declares that every dream of the goal name belongs to a dream of
+// goal, whose id (see getDreamIdField) is set in the foreignKey field of the
+// dream. If the dream has a field named assocName, the associated dream is set
+// in it too. Associated dreams are built by Grow and realized by Realize,
+// it could be changed per call with a Strategy in Lessons:
+//
+// 	gogetter.BelongsTo("Post", "Author", "*User", "AuthorId")
+//
+// 	gogetter.Realize("Post") // Realize a User as author
+// 	gogetter.Realize("Post", gogetter.Lesson{"Author": gogetter.BuildAssociation})
+// 	gogetter.Realize("Post", gogetter.Lesson{"Author": gogetter.ReuseAssociation(user)})
+// 	gogetter.Realize("Post", gogetter.Lesson{"Author": user}) // Same as reusing user
+// 	gogetter.Realize("Post", gogetter.Lesson{"AuthorId": user.Id}) // Same as reusing user
+//
+// goal could be a pointer goal with a leading asterisk (*), and have traits.
+// The has-one and has-many associations of goal to name by the same foreign key
+// are skipped when the associated dream is made, as the dream is one of them.
+func (r *Registry) BelongsTo(name, assocName, goal, foreignKey string) {
+	r.addAssociation(name, &association{kind: belongsTo, name: assocName, goal: goal, foreignKey: foreignKey, count: 1})
+}
+
+// HasOne declares that every dream of the goal name has a dream of goal, which
+// is made after the dream, with its foreignKey field set to the id of the dream.
+// Dreams of goal which belong to name with the same foreignKey reuse the dream
+// instead of making a new one. See BelongsTo for more details.
+func (r *Registry) HasOne(name, assocName, goal, foreignKey string) {
+	r.addAssociation(name, &association{kind: hasOne, name: assocName, goal: goal, foreignKey: foreignKey, count: 1})
+}
+
+// HasMany is similar to HasOne, except that count dreams of goal are made.
+func (r *Registry) HasMany(name, assocName, goal, foreignKey string, count int) {
+	r.addAssociation(name, &association{kind: hasMany, name: assocName, goal: goal, foreignKey: foreignKey, count: count})
+}
+
+func (r *Registry) addAssociation(name string, assoc *association) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.associations[name] = append(r.associations[name], assoc)
+}
+
+// getOwnAssociations returns associations declared on the goal itself, the
+// ones of parent registries come first.
+func (r *Registry) getOwnAssociations(name string) (assocs []*association) {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		assocs = append(append([]*association{}, reg.associations[name]...), assocs...)
+		reg.mutex.RUnlock()
+	}
+
+	return
+}
+
+// getAssociations returns associations of the goal and its parents.
+func (r *Registry) getAssociations(name string) (assocs []*association) {
+	for _, goal := range r.getGoalChain(name) {
+		assocs = append(assocs, r.getOwnAssociations(goal)...)
+	}
+
+	return
+}
+
+type strategyKind int
+
+const (
+	buildStrategy strategyKind = iota
+	realizeStrategy
+	reuseStrategy
+	skipStrategy
+)
+
+// Strategy decides how associated dreams are made, it's used as a Lesson value
+// with the association name as key.
+type Strategy struct {
+	kind   strategyKind
+	dreams []Dream
+}
+
+var (
+	// Associated dreams are built, even in Realize.
+	BuildAssociation = Strategy{kind: buildStrategy}
+	// Associated dreams are realized, even in Grow.
+	RealizeAssociation = Strategy{kind: realizeStrategy}
+	// Associated dreams are not made at all.
+	SkipAssociation = Strategy{kind: skipStrategy}
+)
+
+// ReuseAssociation uses existing dreams as the associated dreams, they are not
+// modified or tracked again.
+func ReuseAssociation(dreams ...Dream) Strategy {
+	return Strategy{kind: reuseStrategy, dreams: dreams}
+}
+
+// takeStrategies removes Strategies from lessons, and resolves the strategy of
+// every association for every lesson. Other values than Strategies with the
+// association name as key are reused, for has-many associations, slices are
+// reused as the associated dreams.
+func takeStrategies(assocs []*association, lessons []Lesson, saveInDb bool) (taught []Lesson, strategies []map[*association]Strategy) {
+	for _, lesson := range lessons {
+		strategy := map[*association]Strategy{}
+		taughtLesson := Lesson{}
+		for k, v := range lesson {
+			taughtLesson[k] = v
+		}
+
+		for _, assoc := range assocs {
+			if s, ok := taughtLesson[assoc.name].(Strategy); ok {
+				delete(taughtLesson, assoc.name)
+				strategy[assoc] = s
+				continue
+			}
+			if v, ok := taughtLesson[assoc.name]; ok && v != nil {
+				delete(taughtLesson, assoc.name)
+				strategy[assoc] = reuseValue(assoc, v)
+				continue
+			}
+			if _, ok := taughtLesson[assoc.foreignKey]; ok && assoc.kind == belongsTo {
+				strategy[assoc] = SkipAssociation
+				continue
+			}
+			if saveInDb {
+				strategy[assoc] = RealizeAssociation
+			} else {
+				strategy[assoc] = BuildAssociation
+			}
+		}
+
+		if lesson == nil {
+			taughtLesson = nil
+		}
+		taught = append(taught, taughtLesson)
+		strategies = append(strategies, strategy)
+	}
+
+	return
+}
+
+// reuseValue returns the ReuseAssociation of v.
+func reuseValue(assoc *association, v Dream) Strategy {
+	value := reflect.ValueOf(v)
+	if assoc.kind != hasMany || (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) {
+		return ReuseAssociation(v)
+	}
+
+	dreams := []Dream{}
+	for i := 0; i < value.Len(); i++ {
+		dreams = append(dreams, value.Index(i).Interface())
+	}
+
+	return ReuseAssociation(dreams...)
+}
+
+// makeAssociations makes the associated dreams of goals of the kinds. For
+// belongs-to associations, it's called before goals are created, so that
+// foreign keys are set in goals, for the others, it's called after.
+func (gg *GoGetter) makeAssociations(name string, assocs []*association, goals reflect.Value, strategies []map[*association]Strategy, kinds ...associationKind) (err error) {
+	for i := 0; i < goals.Len(); i++ {
+		owner := goals.Index(i)
+		for owner.Kind() == reflect.Ptr {
+			owner = owner.Elem()
+		}
+
+		for _, assoc := range assocs {
+			strategy := strategies[i][assoc]
+			if !assoc.isKindOf(kinds...) || strategy.kind == skipStrategy {
+				continue
+			}
+
+			var dreams []Dream
+			if assoc.kind == belongsTo {
+				dreams, err = gg.makeBelonging(name, assoc, strategy)
+			} else {
+				dreams, err = gg.makeOffspring(name, goals.Index(i).Interface(), assoc, strategy)
+			}
+			if err != nil {
+				return
+			}
+
+			if err = gg.associate(owner, assoc, dreams); err != nil {
+				return &LessonError{Goal: name, Lesson: i, Path: assoc.name, Err: err}
+			}
+		}
+	}
+
+	return
+}
+
+func (gg *GoGetter) makeBelonging(name string, assoc *association, strategy Strategy) (dreams []Dream, err error) {
+	if strategy.kind == reuseStrategy {
+		return strategy.dreams, nil
+	}
+
+	// The goal has-one or has-many name by the same foreign key doesn't make
+	// its offspring, for that the dream being made is one of them.
+	lesson := Lesson{}
+	goal, _ := gg.registry.parseTraits(trimPointer(assoc.goal))
+	if cycle := gg.registry.findAssociationCycle(goal, belongsTo); cycle != nil {
+		return nil, cyclicAssociationsError(name, assoc, cycle)
+	}
+	for _, back := range gg.registry.getAssociations(goal) {
+		backGoal, _ := gg.registry.parseTraits(trimPointer(back.goal))
+		if back.kind != belongsTo && back.foreignKey == assoc.foreignKey && backGoal == name {
+			lesson[back.name] = SkipAssociation
+		}
+	}
+
+	dream, err := gg.makeDreams(assoc.goal, strategy.kind == realizeStrategy, lesson)
+	return []Dream{dream}, err
+}
+
+// makeOffspring makes dreams of has-one and has-many associations, with their
+// foreign keys set to the id of owner.
+func (gg *GoGetter) makeOffspring(name string, owner Dream, assoc *association, strategy Strategy) (dreams []Dream, err error) {
+	if strategy.kind == reuseStrategy {
+		return strategy.dreams, nil
+	}
+	if assoc.count <= 0 {
+		return
+	}
+
+	idField := gg.registry.getDreamIdField(name, reflect.TypeOf(owner))
+	if idField == "" {
+		return nil, errors.New("gogetter: Id Field of " + name + " is Not Exist")
+	}
+	lesson := Lesson{assoc.foreignKey: gg.retrieveDreamId(owner, idField)}
+	// Offspring which belong to the same goal by the same foreign key reuse
+	// the owner, instead of making a new one.
+	goal, _ := gg.registry.parseTraits(trimPointer(assoc.goal))
+	if cycle := gg.registry.findAssociationCycle(goal, hasOne, hasMany); cycle != nil {
+		return nil, cyclicAssociationsError(name, assoc, cycle)
+	}
+	for _, back := range gg.registry.getAssociations(goal) {
+		if back.kind == belongsTo && back.foreignKey == assoc.foreignKey {
+			lesson[back.name] = ReuseAssociation(owner)
+		}
+	}
+
+	lessons := []Lesson{}
+	for i := 0; i < assoc.count; i++ {
+		lessons = append(lessons, lesson)
+	}
+	dream, err := gg.makeDreams(assoc.goal, strategy.kind == realizeStrategy, lessons...)
+	if err != nil {
+		return
+	}
+	if assoc.count == 1 {
+		return []Dream{dream}, nil
+	}
+	v := reflect.ValueOf(dream)
+	for i := 0; i < v.Len(); i++ {
+		dreams = append(dreams, v.Index(i).Interface())
+	}
+
+	return
+}
+
+// findAssociationCycle returns a cycle of associations of the kinds reachable
+// from goal, e.g. ["Comment", "Comment"] for a comment belonging to a comment,
+// or nil. Associated dreams in a cycle would be made endlessly, as they are
+// made with the default strategies.
+func (r *Registry) findAssociationCycle(goal string, kinds ...associationKind) []string {
+	path := []string{}
+	visiting := map[string]bool{}
+	visited := map[string]bool{}
+
+	var visit func(goal string) []string
+	visit = func(goal string) []string {
+		if visiting[goal] {
+			for i, g := range path {
+				if g == goal {
+					return append(append([]string{}, path[i:]...), goal)
+				}
+			}
+		}
+		if visited[goal] {
+			return nil
+		}
+
+		visiting[goal] = true
+		path = append(path, goal)
+		for _, assoc := range r.getAssociations(goal) {
+			if !assoc.isKindOf(kinds...) || assoc.count <= 0 {
+				continue
+			}
+			next, _ := r.parseTraits(trimPointer(assoc.goal))
+			if cycle := visit(next); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		visiting[goal] = false
+		visited[goal] = true
+
+		return nil
+	}
+
+	return visit(goal)
+}
+
+func cyclicAssociationsError(name string, assoc *association, cycle []string) error {
+	return fmt.Errorf("gogetter: association %q of %q is cyclic (%s), make it with a Strategy", assoc.name, name, strings.Join(cycle, " -> "))
+}
+
+// associate sets the foreign key of owner for belongs-to associations, and the
+// field named after the association, if any, to the associated dreams.
+func (gg *GoGetter) associate(owner reflect.Value, assoc *association, dreams []Dream) (err error) {
+	tag := gg.getOptions().lessonTag
+	if assoc.kind == belongsTo && len(dreams) > 0 {
+		goal, _ := gg.registry.parseTraits(trimPointer(assoc.goal))
+		idField := gg.registry.getDreamIdField(goal, reflect.TypeOf(dreams[0]))
+		if idField == "" {
+			return errors.New("gogetter: Id Field of " + goal + " is Not Exist")
+		}
+		err = setFieldPath(owner, assoc.foreignKey, tag, func(field reflect.Value) error {
+			return gg.assign(field, gg.retrieveDreamId(dreams[0], idField))
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	if _, ok := lookupField(owner.Type(), assoc.name, tag); !ok {
+		return
+	}
+
+	return setFieldPath(owner, assoc.name, tag, func(field reflect.Value) error {
+		if assoc.kind != hasMany {
+			if len(dreams) == 0 {
+				return nil
+			}
+			return gg.assign(field, fitDream(dreams[0], field.Type()))
+		}
+
+		if field.Kind() != reflect.Slice {
+			return &TypeMismatchError{Expected: field.Type(), Actual: reflect.TypeOf(dreams)}
+		}
+		s := reflect.MakeSlice(field.Type(), len(dreams), len(dreams))
+		for i, dream := range dreams {
+			if err := gg.assign(s.Index(i), fitDream(dream, s.Index(i).Type())); err != nil {
+				return err
+			}
+		}
+		field.Set(s)
+
+		return nil
+	})
+}
+
+// fitDream dereferences or takes the address of dream, if it makes dream
+// assignable to t, so that *User could be associated to a User field, or the
+// other way around.
+func fitDream(dream Dream, t reflect.Type) Dream {
+	v := reflect.ValueOf(dream)
+	for v.Kind() == reflect.Ptr && !v.IsNil() && !v.Type().AssignableTo(t) {
+		v = v.Elem()
+	}
+	if v.Type().AssignableTo(t) {
+		return v.Interface()
+	}
+	if reflect.PtrTo(v.Type()).AssignableTo(t) {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return p.Interface()
+	}
+
+	return dream
+}
+
+func (assoc *association) isKindOf(kinds ...associationKind) bool {
+	for _, kind := range kinds {
+		if assoc.kind == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func trimPointer(name string) string {
+	if len(name) > 1 && name[0] == '*' {
+		return name[1:]
+	}
+
+	return name
+}
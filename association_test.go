@@ -0,0 +1,128 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type Author struct {
+	Id      int
+	Name    string
+	Profile *Profile
+	Posts   []*Post
+}
+
+type Profile struct {
+	Id       int
+	AuthorId int
+}
+
+type Post struct {
+	Id       int
+	AuthorId int
+	Author   *Author
+}
+
+func init() {
+	SetGoal("Author", func() Dream { return Author{Name: "author"} })
+	SetSequence("Author", "Id", "")
+	HasOne("Author", "Profile", "*Profile", "AuthorId")
+	HasMany("Author", "Posts", "*Post", "AuthorId", 2)
+
+	SetGoal("Profile", func() Dream { return Profile{} })
+	SetSequence("Profile", "Id", "")
+
+	SetGoal("Post", func() Dream { return Post{} })
+	SetSequence("Post", "Id", "")
+	BelongsTo("Post", "Author", "*Author", "AuthorId")
+}
+
+func (s *GoGetterSuite) TestBelongsTo(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	postI, err := gg.Realize("Post", Lesson{"Author": BuildAssociation})
+	c.Check(err, Equals, nil)
+	post := postI.(Post)
+	c.Check(post.Author.Name, Equals, "author")
+	c.Check(post.AuthorId, Equals, post.Author.Id)
+	c.Check(post.Author.Posts, HasLen, 0)
+	c.Check(db.created["authors"], HasLen, 0)
+	c.Check(db.created["posts"], HasLen, 1)
+	c.Check(gg.dreams["Author"], HasLen, 1)
+
+	postsI, err := gg.Grow("Post", Lesson{"Author": ReuseAssociation(post.Author)}, Lesson{"AuthorId": 100})
+	c.Check(err, Equals, nil)
+	posts := postsI.([]Post)
+	c.Check(posts[0].Author, Equals, post.Author)
+	c.Check(posts[0].AuthorId, Equals, post.Author.Id)
+	c.Check(posts[1].Author, IsNil)
+	c.Check(posts[1].AuthorId, Equals, 100)
+	c.Check(gg.dreams["Author"], HasLen, 1)
+}
+
+func (s *GoGetterSuite) TestHasOneAndHasMany(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	authorI, err := gg.Realize("*Author")
+	c.Check(err, Equals, nil)
+	author := authorI.(*Author)
+	c.Check(author.Profile.AuthorId, Equals, author.Id)
+	c.Assert(author.Posts, HasLen, 2)
+	for _, post := range author.Posts {
+		c.Check(post.AuthorId, Equals, author.Id)
+		c.Check(post.Author, Equals, author)
+	}
+	c.Check(db.created["authors"], HasLen, 1)
+	c.Check(db.created["profiles"], HasLen, 1)
+	c.Check(db.created["posts"], HasLen, 2)
+	c.Check(gg.dreams["Post"], HasLen, 2)
+
+	authorI, err = gg.Realize("Author", Lesson{"Posts": SkipAssociation, "Profile": BuildAssociation})
+	c.Check(err, Equals, nil)
+	c.Check(authorI.(Author).Posts, HasLen, 0)
+	c.Check(authorI.(Author).Profile, NotNil)
+	c.Check(db.created["profiles"], HasLen, 1)
+	c.Check(gg.dreams["Profile"], HasLen, 2)
+}
+
+func (s *GoGetterSuite) TestReuseAssociationValues(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	author := &Author{Id: 100, Name: "Van"}
+	postI, err := gg.Realize("Post", Lesson{"Author": author})
+	c.Check(err, Equals, nil)
+	c.Check(postI.(Post).Author, Equals, author)
+	c.Check(postI.(Post).AuthorId, Equals, 100)
+	c.Check(db.created["authors"], HasLen, 0)
+
+	posts := []*Post{{Id: 1000}}
+	authorI, err := gg.Realize("Author", Lesson{"Posts": posts, "Profile": SkipAssociation})
+	c.Check(err, Equals, nil)
+	c.Check(authorI.(Author).Posts, DeepEquals, posts)
+	c.Check(db.created["posts"], HasLen, 1)
+}
+
+type Comment struct {
+	Id       int
+	ParentId int
+	Replies  []Comment
+}
+
+func (s *GoGetterSuite) TestCyclicAssociations(c *C) {
+	reg := NewRegistry(nil)
+	reg.SetGoal("Comment", func() Dream { return Comment{} })
+	reg.BelongsTo("Comment", "Parent", "Comment", "ParentId")
+	gg := NewGoGetter(newFakeDb(), reg)
+
+	_, err := gg.Realize("Comment")
+	c.Check(err, ErrorMatches, `gogetter: association "Parent" of "Comment" is cyclic \(Comment -> Comment\), make it with a Strategy`)
+	_, err = gg.Realize("Comment", Lesson{"Parent": SkipAssociation})
+	c.Check(err, Equals, nil)
+	_, err = gg.Realize("Comment", Lesson{"ParentId": 1})
+	c.Check(err, Equals, nil)
+
+	reg = NewRegistry(nil)
+	reg.SetGoal("Comment", func() Dream { return Comment{} })
+	reg.HasMany("Comment", "Replies", "Comment", "ParentId", 1)
+	_, err = NewGoGetter(newFakeDb(), reg).Realize("Comment")
+	c.Check(err, ErrorMatches, `gogetter: association "Replies" of "Comment" is cyclic \(Comment -> Comment\).*`)
+}
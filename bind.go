@@ -0,0 +1,114 @@
+package gogetter
+
+import (
+	"sort"
+)
+
+// TB is the part of testing.TB used by Binding, *testing.T, *testing.B and
+// gocheck's *C all satisfy it.
+type TB interface {
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Logf(format string, args ...interface{})
+	Failed() bool
+}
+
+// Binding is a GoGetter bound to a test, see (gg *GoGetter) Bind.
+type Binding struct {
+	gg *GoGetter
+	t  TB
+}
+
+// See (gg *GoGetter) Bind.
+func Bind(t TB) *Binding {
+	return defaultGetter.Bind(t)
+}
+
+// Bind returns a Binding making dreams for the test t in a new scope of the
+// GoGetter, see (gg *GoGetter) Scope. Dreams made by the Binding are destroyed
+// when the test finishes, via t.Cleanup if t has it, e.g.
+// *testing.T, otherwise Close must be called, e.g. in TearDownTest of gocheck
+// suites.
+//
+// 	func TestPost(t *testing.T) {
+// 		g := getter.Bind(t)
+// 		post := g.Realize("*Post").(*Post) // Fails the test if Realize fails
+// 		...
+// 	}
+//
+// If the test failed, what was made is logged before being destroyed.
+func (gg *GoGetter) Bind(t TB) *Binding {
+	b := &Binding{gg: gg.Scope(), t: t}
+	if c, ok := t.(interface {
+		Cleanup(func())
+	}); ok {
+		c.Cleanup(b.Close)
+	}
+
+	return b
+}
+
+// GoGetter returns the scope used by the Binding, which tracks only dreams
+// made by the Binding.
+func (b *Binding) GoGetter() *GoGetter {
+	return b.gg
+}
+
+// Grow is similar to (gg *GoGetter) Grow, except that errors fail the test.
+func (b *Binding) Grow(name string, lessons ...Lesson) Dream {
+	b.helper()
+	dreams, err := b.gg.Grow(name, lessons...)
+	if err != nil {
+		b.t.Fatalf("gogetter: Grow %q: %s", name, err)
+	}
+
+	return dreams
+}
+
+// Realize is similar to (gg *GoGetter) Realize, except that errors fail the test.
+func (b *Binding) Realize(name string, lessons ...Lesson) Dream {
+	b.helper()
+	dreams, err := b.gg.Realize(name, lessons...)
+	if err != nil {
+		b.t.Fatalf("gogetter: Realize %q: %s", name, err)
+	}
+
+	return dreams
+}
+
+// Close destroys dreams made by the Binding, and logs them if the test failed.
+// It's safe to call Close more than once.
+func (b *Binding) Close() {
+	b.helper()
+	if b.t.Failed() {
+		b.logDreams()
+	}
+	if err := b.gg.Close(); err != nil {
+		b.t.Errorf("gogetter: cleanup: %s", err)
+	}
+}
+
+// logDreams logs dreams made by the binding and its open scopes.
+func (b *Binding) logDreams() {
+	names := map[string]bool{}
+	b.gg.collectGoals(names)
+	sorted := []string{}
+	for name, _ := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		for _, dream := range b.gg.Dreams(name) {
+			b.t.Logf("gogetter: made %q: %+v", name, dream)
+		}
+	}
+}
+
+func (b *Binding) helper() {
+	if h, ok := b.t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+}
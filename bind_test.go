@@ -0,0 +1,97 @@
+package gogetter
+
+import (
+	"fmt"
+	. "launchpad.net/gocheck"
+	"strings"
+	"testing"
+)
+
+// fakeT records what a Binding reports.
+type fakeT struct {
+	errors   []string
+	fatals   []string
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Failed() bool {
+	return len(t.errors)+len(t.fatals) > 0
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func TestBindCleansUp(t *testing.T) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	gg.Realize("Member")
+
+	t.Run("bound", func(t *testing.T) {
+		g := gg.Bind(t)
+		member := g.Realize("Member").(Member)
+		if member.Id == 0 {
+			t.Errorf("expected a member with id, got %+v", member)
+		}
+		g.Grow("*Member")
+	})
+
+	if len(db.removed["members"]) != 2 {
+		t.Errorf("expected 2 members removed, got %v", db.removed["members"])
+	}
+	if len(gg.dreams["Member"]) != 1 {
+		t.Errorf("expected dreams of the getter untouched, got %v", gg.dreams["Member"])
+	}
+}
+
+func TestBindReportsErrors(t *testing.T) {
+	ft := &fakeT{}
+	g := NewGoGetter(newFakeDb()).Bind(ft)
+	g.Grow("Not Exist")
+	if len(ft.fatals) != 1 || !strings.Contains(ft.fatals[0], `"Not Exist"`) {
+		t.Errorf("expected a fatal error with the goal name, got %v", ft.fatals)
+	}
+
+	g.Realize("Member", Lesson{"Name": "Van"})
+	g.gg.Scope().Realize("Member", Lesson{"Name": "Gogh"})
+	if len(ft.cleanups) != 1 {
+		t.Fatalf("expected a registered cleanup, got %d", len(ft.cleanups))
+	}
+	ft.cleanups[0]()
+	if len(ft.logs) != 2 || !strings.Contains(ft.logs[0], "Van") || !strings.Contains(ft.logs[1], "Gogh") {
+		t.Errorf("expected made dreams of the binding and its scopes logged, got %v", ft.logs)
+	}
+}
+
+func TestBindReportsCleanupErrors(t *testing.T) {
+	ft := &fakeT{}
+	db := newFakeDb()
+	db.failures["members"] = fmt.Errorf("locked")
+	g := NewGoGetter(db).Bind(ft)
+	g.Realize("Member")
+	g.Close()
+	if len(ft.errors) != 1 || !strings.Contains(ft.errors[0], "locked") {
+		t.Errorf("expected the cleanup error reported, got %v", ft.errors)
+	}
+}
+
+func (s *GoGetterSuite) TestBindWithGocheck(c *C) {
+	db := newFakeDb()
+	g := NewGoGetter(db).Bind(c)
+	member := g.Realize("Member").(Member)
+	g.Close()
+	c.Check(db.removed["members"], DeepEquals, []interface{}{member.Id})
+}
@@ -0,0 +1,263 @@
+package gogetter
+
+import (
+	"errors"
+	"sort"
+)
+
+// CleanupOrder decides the order in which Apocalypse destroys goals.
+type CleanupOrder int
+
+const (
+	// Goals are destroyed one by one, dependents before their dependencies, and
+	// then the ones created later before the earlier ones. It's the default order.
+	ReverseCreationOrder CleanupOrder = iota
+	// Goals independent of each other are destroyed concurrently, dependents
+	// are still destroyed before their dependencies.
+	ConcurrentCleanup
+)
+
+// See (r *Registry) DependsOn.
+func DependsOn(name string, goals ...string) {
+	defaultRegistry.DependsOn(name, goals...)
+}
+
+// DependsOn declares that records of the goal name depend on the ones of goals,
+// e.g. by foreign keys, so Apocalypse destroys name before goals. Associations
+// declare dependencies implicitly, a goal belonging to another depends on it.
+// Dependencies are inherited through AscendGoal.
+func (r *Registry) DependsOn(name string, goals ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.dependencies[name] = append(r.dependencies[name], goals...)
+}
+
+// CleanupStrategy decides how GoGetters destroy what they made.
+type CleanupStrategy int
+
+const (
+	// Records are removed by their ids, goal by goal. It's the default strategy.
+	CleanupById CleanupStrategy = iota
+	// Records are made in a transaction, which is rolled back to destroy them,
+	// along with the records made by the code under test in the transaction.
+	// The Database must be Transactional.
+	CleanupByRollback
+	// Tables of the goals are truncated, along with the records made by the
+	// code under test in them. The Database must be a Truncater.
+	CleanupByTruncation
+)
+
+// Truncater is implemented by Databases able to remove all records of tables,
+// which is required by CleanupByTruncation. Tables are passed in the order
+// that dependents come before their dependencies, drivers should take care of
+// constraints between them, e.g. foreign keys.
+type Truncater interface {
+	Truncate(tables ...string) error
+}
+
+var ErrNotTruncater = errors.New("gogetter: database doesn't support truncation")
+
+// See (gg *GoGetter) SetCleanupStrategy.
+func SetCleanupStrategy(strategy CleanupStrategy) {
+	defaultGetter.SetCleanupStrategy(strategy)
+}
+
+// SetCleanupStrategy sets how the GoGetter destroys what it made, the default
+// one is CleanupById.
+//
+// With CleanupByTruncation, Apocalypse and Close truncate tables of the goals
+// instead of removing records by ids. Tables are shared, truncating them in a
+// scope destroys the records made by its parent in them too.
+//
+// With CleanupByRollback, a transaction is begun for the GoGetter when it's
+// firstly needed, and Apocalypse and Close roll it back. Scopes of the GoGetter
+// begin nested transactions in it, so closing a scope only rolls back what's
+// made in the scope.
+//
+// Records made in the transaction are only visible to it, the code under test
+// should use the transaction returned by Db:
+//
+// 	getter.SetCleanupStrategy(gogetter.CleanupByRollback)
+// 	defer getter.Close()
+// 	db, err := getter.Db()
+func (gg *GoGetter) SetCleanupStrategy(strategy CleanupStrategy) {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
+
+	gg.options.cleanupStrategy = strategy
+}
+
+// See (gg *GoGetter) SetCleanupOrder.
+func SetCleanupOrder(order CleanupOrder) {
+	defaultGetter.SetCleanupOrder(order)
+}
+
+// SetCleanupOrder sets the order in which Apocalypse destroys goals, the default
+// one is ReverseCreationOrder.
+func (gg *GoGetter) SetCleanupOrder(order CleanupOrder) {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
+
+	gg.options.cleanupOrder = order
+}
+
+// See (gg *GoGetter) SetStopOnCleanupError.
+func SetStopOnCleanupError(stop bool) {
+	defaultGetter.SetStopOnCleanupError(stop)
+}
+
+// SetStopOnCleanupError makes Apocalypse stop after the first failure, goals
+// being destroyed concurrently with the failed one are still destroyed.
+func (gg *GoGetter) SetStopOnCleanupError(stop bool) {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
+
+	gg.options.stopOnCleanupError = stop
+}
+
+// getDependencies returns names of the goals that name directly depends on.
+func (r *Registry) getDependencies(name string) (deps []string) {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		deps = append(deps, reg.dependencies[name]...)
+		reg.mutex.RUnlock()
+	}
+	for _, assoc := range r.getOwnAssociations(name) {
+		goal, _ := r.parseTraits(trimPointer(assoc.goal))
+		if assoc.kind == belongsTo {
+			deps = append(deps, goal)
+		}
+	}
+
+	return
+}
+
+// dependsOn checks whether a depends on b, directly or through parents of
+// them, e.g. "Post" depends on "Super User" if it belongs to "User".
+func (r *Registry) dependsOn(a, b string) bool {
+	// has-one and has-many associations make the associated goals dependents
+	for _, bName := range r.getGoalChain(b) {
+		for _, assoc := range r.getOwnAssociations(bName) {
+			goal, _ := r.parseTraits(trimPointer(assoc.goal))
+			if assoc.kind == belongsTo {
+				continue
+			}
+			for _, aName := range r.getGoalChain(a) {
+				if goal == aName {
+					return true
+				}
+			}
+		}
+	}
+
+	bChain := r.getGoalChain(b)
+	for _, aName := range r.getGoalChain(a) {
+		for _, dep := range r.getDependencies(aName) {
+			for _, bName := range bChain {
+				if dep == bName {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// cleanupLayers sorts names into layers, names in each layer could be destroyed
+// concurrently. With ReverseCreationOrder, every layer has only one name.
+func (gg *GoGetter) cleanupLayers(names []string) (layers [][]string) {
+	gg.mutex.Lock()
+	creations := map[string]int{}
+	for k, v := range gg.creations {
+		creations[k] = v
+	}
+	order := gg.options.cleanupOrder
+	gg.mutex.Unlock()
+
+	remains := append([]string{}, names...)
+	sort.SliceStable(remains, func(i, j int) bool {
+		ci, iok := creations[remains[i]]
+		cj, jok := creations[remains[j]]
+		if iok != jok {
+			return iok
+		}
+		return ci > cj
+	})
+
+	for len(remains) > 0 {
+		layer := []string{}
+		rest := []string{}
+		for _, name := range remains {
+			if order == ReverseCreationOrder && len(layer) > 0 {
+				rest = append(rest, name)
+				continue
+			}
+			hasDependents := false
+			for _, other := range remains {
+				if other != name && gg.registry.dependsOn(other, name) {
+					hasDependents = true
+					break
+				}
+			}
+			if hasDependents {
+				rest = append(rest, name)
+			} else {
+				layer = append(layer, name)
+			}
+		}
+
+		// Circular dependencies, destroy the latest created one first.
+		if len(layer) == 0 {
+			layer, rest = rest[:1], rest[1:]
+		}
+		layers = append(layers, layer)
+		remains = rest
+	}
+
+	return
+}
+
+// truncate truncates tables of the goals in one go, and forgets their dreams.
+func (gg *GoGetter) truncate(names []string) (err error) {
+	tables := []string{}
+	truncated := map[string]bool{}
+	for _, layer := range gg.cleanupLayers(names) {
+		for _, name := range layer {
+			table, er := gg.registry.GetTableName(name)
+			if er != nil {
+				return &ApocalypseError{Errors: []*CleanupError{{Goal: name, Err: er}}}
+			}
+			if !truncated[table] {
+				truncated[table] = true
+				tables = append(tables, table)
+			}
+		}
+	}
+
+	if db := gg.getDb(); db != nil && len(tables) > 0 {
+		t, ok := db.(Truncater)
+		if !ok {
+			err = ErrNotTruncater
+		} else {
+			err = t.Truncate(tables...)
+		}
+	}
+	if err != nil {
+		apocalypseErr := &ApocalypseError{}
+		for _, name := range names {
+			table, _ := gg.registry.GetTableName(name)
+			apocalypseErr.Errors = append(apocalypseErr.Errors, &CleanupError{Goal: name, Table: table, Err: err})
+		}
+		return apocalypseErr
+	}
+
+	gg.mutex.Lock()
+	for _, name := range names {
+		delete(gg.dreams, name)
+	}
+	gg.mutex.Unlock()
+
+	return
+}
@@ -0,0 +1,99 @@
+package gogetter
+
+import (
+	"errors"
+	. "launchpad.net/gocheck"
+)
+
+type Tag struct {
+	Id     int
+	PostId int
+}
+
+func init() {
+	SetGoal("Tag", func() Dream { return Tag{} })
+	SetSequence("Tag", "Id", "")
+	DependsOn("Tag", "Post")
+
+	AscendGoal("Guest Author", "Author", func() Lesson { return Lesson{} })
+}
+
+func (s *GoGetterSuite) TestCleanupInReverseCreationOrder(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	gg.Realize("Member")
+	gg.Realize("Fixture")
+	gg.Realize("Account")
+	err := gg.Apocalypse()
+	c.Check(err, Equals, nil)
+	c.Check(db.removals, DeepEquals, []string{"accounts", "fixtures", "members"})
+}
+
+func (s *GoGetterSuite) TestCleanupInDependencyOrder(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	gg.Realize("Tag", Lesson{"PostId": 1})
+	gg.Realize("Post", Lesson{"Author": SkipAssociation})
+	gg.Realize("Guest Author", Lesson{"Posts": SkipAssociation})
+	gg.Realize("Profile")
+	err := gg.Apocalypse()
+	c.Check(err, Equals, nil)
+	c.Check(db.removals, DeepEquals, []string{"profiles", "tags", "posts", "authors"})
+}
+
+func (s *GoGetterSuite) TestConcurrentCleanup(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	gg.SetCleanupOrder(ConcurrentCleanup)
+	gg.Realize("Author")
+	gg.Realize("Member")
+	c.Check(gg.cleanupLayers([]string{"Author", "Post", "Profile", "Member"}), DeepEquals, [][]string{
+		{"Member", "Post", "Profile"},
+		{"Author"},
+	})
+	err := gg.Apocalypse()
+	c.Check(err, Equals, nil)
+	c.Check(db.removals, HasLen, 4)
+	c.Check(db.removals[3], Equals, "authors")
+}
+
+func (s *GoGetterSuite) TestApocalypseError(c *C) {
+	db := newFakeDb()
+	driverErr := errors.New("driver error")
+	db.failures["accounts"] = driverErr
+	db.failures["members"] = driverErr
+	gg := NewGoGetter(db)
+	gg.Realize("Member")
+	gg.Realize("Fixture")
+	gg.Realize("Account")
+
+	err := gg.Apocalypse()
+	apocalypseErr := &ApocalypseError{}
+	c.Assert(errors.As(err, &apocalypseErr), Equals, true)
+	c.Assert(apocalypseErr.Errors, HasLen, 2)
+	c.Check(apocalypseErr.Errors[0].Goal, Equals, "Account")
+	c.Check(apocalypseErr.Errors[0].Table, Equals, "accounts")
+	c.Check(apocalypseErr.Errors[1].Goal, Equals, "Member")
+	c.Check(errors.Is(err, driverErr), Equals, true)
+	c.Check(db.removals, DeepEquals, []string{"fixtures"})
+	c.Check(err.Error(), Equals, `gogetter: failed to destroy "Account" in table "accounts": driver error`+"\n"+
+		`gogetter: failed to destroy "Member" in table "members": driver error`)
+
+	cleanupErr := &CleanupError{}
+	c.Check(errors.As(err, &cleanupErr), Equals, true)
+	c.Check(cleanupErr.Goal, Equals, "Account")
+}
+
+func (s *GoGetterSuite) TestStopOnCleanupError(c *C) {
+	db := newFakeDb()
+	db.failures["accounts"] = errors.New("driver error")
+	gg := NewGoGetter(db)
+	gg.SetStopOnCleanupError(true)
+	gg.Realize("Member")
+	gg.Realize("Account")
+
+	err := gg.Apocalypse()
+	c.Check(err, ErrorMatches, ".*driver error")
+	c.Check(db.removals, HasLen, 0)
+	c.Check(gg.dreams["Member"], HasLen, 1)
+}
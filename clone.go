@@ -0,0 +1,27 @@
+package gogetter
+
+import (
+	"github.com/bom-d-van/gogetter/internal/clone"
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// deepCopy copies src into dst, see clone.DeepCopy for details. Dreams never
+// share any slices, maps or pointers with the values returned by Goals or with
+// each other, except for fields with a `gogetter:"shared"` tag.
+func deepCopy(dst, src reflect.Value) {
+	clone.DeepCopy(dst, src)
+}
+
+// copyDream returns a deep copy of dream, see deepCopy.
+func copyDream(dream Dream) Dream {
+	return clone.Copy(dream)
+}
+
+// hasGogetterTag checks whether the gogetter tag of the field, which could be
+// a comma separated list like `gogetter:"id,shared"`, contains the option.
+func hasGogetterTag(field reflect.StructField, option string) bool {
+	return clone.HasTag(field, option)
+}
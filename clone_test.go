@@ -0,0 +1,69 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+	"reflect"
+	"time"
+)
+
+type Node struct {
+	Name     string
+	Next     *Node
+	Children map[string]*Node
+	Born     time.Time
+	Shared   *DreamS `gogetter:"shared"`
+
+	secret *DreamS
+}
+
+var sharedDream = &DreamS{Title: "Shared Dream"}
+
+func init() {
+	SetGoal("Node", func() Dream {
+		node := &Node{
+			Name:     "root",
+			Children: map[string]*Node{},
+			Born:     time.Date(2014, 1, 6, 0, 0, 0, 0, time.UTC),
+			Shared:   sharedDream,
+			secret:   &DreamS{Title: "Secret"},
+		}
+		node.Next = node
+		node.Children["self"] = node
+		return node
+	})
+}
+
+func (s *GoGetterSuite) TestDreamsShareNothing(c *C) {
+	dream := &DreamS{Title: "My Dream"}
+	SetGoal("Shared Pointer User", func() Dream {
+		user := makeUser()
+		user.Dream = dream
+		return user
+	})
+
+	usersI, err := Grow("Shared Pointer User", nil, nil)
+	c.Check(err, Equals, nil)
+	users := usersI.([]User)
+	users[0].Dream.Title = "Changed"
+	users[0].VisitedPlaces[0] = "Changed"
+	c.Check(users[1].Dream.Title, Equals, "My Dream")
+	c.Check(users[1].VisitedPlaces[0], Equals, "New York City")
+	c.Check(dream.Title, Equals, "My Dream")
+}
+
+func (s *GoGetterSuite) TestDeepCopyCyclesAndUnexportedFields(c *C) {
+	nodeI, err := Grow("Node")
+	c.Check(err, Equals, nil)
+	node := nodeI.(*Node)
+	c.Check(node.Next, Equals, node)
+	c.Check(node.Children["self"], Equals, node)
+	c.Check(node.Born.Equal(time.Date(2014, 1, 6, 0, 0, 0, 0, time.UTC)), Equals, true)
+	c.Check(node.Shared, Equals, sharedDream)
+	c.Check(node.secret.Title, Equals, "Secret")
+
+	original := &Node{secret: &DreamS{Title: "Secret"}}
+	cloned := &Node{}
+	deepCopy(reflect.ValueOf(cloned).Elem(), reflect.ValueOf(original).Elem())
+	c.Check(cloned.secret, Not(Equals), original.secret)
+	c.Check(cloned.secret.Title, Equals, "Secret")
+}
@@ -0,0 +1,69 @@
+package gogetter
+
+import (
+	"fmt"
+	. "launchpad.net/gocheck"
+	"sync"
+)
+
+type Racer struct {
+	Id   int
+	Name string
+}
+
+func (s *GoGetterSuite) TestConcurrentUse(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+
+	errs := make(chan error, 100)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("Racer %d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			SetGoal(name, func() Dream { return Racer{} })
+			SetSequence(name, "Id", "")
+			SetTrait(name, "fast", func() Lesson { return Lesson{"Name": "fast"} })
+			AscendGoal("Child "+name, name, func() Lesson { return Lesson{} })
+			gg.SetConversion(true)
+			if _, err := GetTableName("Child " + name); err != nil {
+				errs <- err
+			}
+			for j := 0; j < 5; j++ {
+				if _, err := gg.Realize(name+":fast", Lesson{"Name": fmt.Sprint(j)}, nil); err != nil {
+					errs <- err
+				}
+				if _, err := gg.Grow("*Child " + name); err != nil {
+					errs <- err
+				}
+				if _, err := gg.Realize("Member"); err != nil {
+					errs <- err
+				}
+				if err := gg.AllInVain(name); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			if err := gg.Apocalypse(); err != nil {
+				errs <- err
+			}
+		}
+	}()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		c.Error(err)
+	}
+	c.Check(gg.Apocalypse(), Equals, nil)
+	c.Check(gg.dreams["Racer 0"], HasLen, 0)
+	c.Check(db.created["racer_0s"], HasLen, 10)
+	c.Check(db.created["members"], HasLen, 50)
+}
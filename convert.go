@@ -0,0 +1,159 @@
+package gogetter
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Converter converts a Lesson value into a value of the type it's registered for.
+type Converter func(v Dream) (Dream, error)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Layouts tried when converting strings into time.Time.
+var TimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// See (r *Registry) RegisterConverter.
+func RegisterConverter(t reflect.Type, converter Converter) {
+	defaultRegistry.RegisterConverter(t, converter)
+}
+
+// RegisterConverter makes gogetter use the converter for fields of type t, when
+// conversion is enabled and the Lesson value is not assignable to the field.
+//
+// 	gogetter.RegisterConverter(reflect.TypeOf(decimal.Decimal{}), func(v gogetter.Dream) (gogetter.Dream, error) {
+// 		return decimal.NewFromFloat(v.(float64)), nil
+// 	})
+func (r *Registry) RegisterConverter(t reflect.Type, converter Converter) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.converters[t] = converter
+}
+
+func (r *Registry) getConverter(t reflect.Type) Converter {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		converter, ok := reg.converters[t]
+		reg.mutex.RUnlock()
+		if ok {
+			return converter
+		}
+	}
+
+	return nil
+}
+
+// See (gg *GoGetter) SetConversion.
+func SetConversion(enabled bool) {
+	defaultGetter.SetConversion(enabled)
+}
+
+// By default, Lesson values must be assignable to their fields. SetConversion
+// enables gogetter to convert them, so that Lessons loaded from YAML/JSON could
+// be used directly. Values are converted in the following order:
+//
+// 	1. Converters registered with RegisterConverter for the field type.
+// 	2. Strings are parsed for time.Time (using TimeLayouts) and time.Duration,
+// 	   or unmarshaled if the field implements encoding.TextUnmarshaler or
+// 	   json.Unmarshaler, e.g. hex strings into bson.ObjectId.
+// 	3. reflect.Convert for convertible kinds, except numbers into strings and
+// 	   floats with fractions into integers.
+//
+// Values are also converted into pointer fields of the types above.
+func (gg *GoGetter) SetConversion(enabled bool) {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
+
+	gg.options.conversion = enabled
+}
+
+// convert converts value to type t, ok is false if it is not convertible.
+func (r *Registry) convert(value reflect.Value, t reflect.Type) (converted reflect.Value, ok bool, err error) {
+	if converter := r.getConverter(t); converter != nil {
+		var v Dream
+		if v, err = converter(value.Interface()); err != nil {
+			return
+		}
+		converted = reflect.ValueOf(v)
+		ok = converted.IsValid() && converted.Type().AssignableTo(t)
+		return
+	}
+
+	if value.Kind() == reflect.String {
+		if converted, ok, err = convertString(value.String(), t); ok || err != nil {
+			return
+		}
+	}
+
+	if value.Type().ConvertibleTo(t) && isSafeConversion(value, t) {
+		return value.Convert(t), true, nil
+	}
+
+	if t.Kind() == reflect.Ptr {
+		var elem reflect.Value
+		if elem, ok, err = r.convert(value, t.Elem()); ok {
+			converted = reflect.New(t.Elem())
+			converted.Elem().Set(elem)
+		}
+	}
+
+	return
+}
+
+func convertString(s string, t reflect.Type) (converted reflect.Value, ok bool, err error) {
+	switch t {
+	case timeType:
+		for _, layout := range TimeLayouts {
+			var tm time.Time
+			if tm, err = time.Parse(layout, s); err == nil {
+				return reflect.ValueOf(tm), true, nil
+			}
+		}
+		return
+	case durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err != nil {
+			return
+		}
+		return reflect.ValueOf(d), true, nil
+	}
+
+	converted = reflect.New(t)
+	switch u := converted.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		err = u.UnmarshalText([]byte(s))
+	case json.Unmarshaler:
+		err = u.UnmarshalJSON([]byte(strconv.Quote(s)))
+	default:
+		return reflect.Value{}, false, nil
+	}
+	if err != nil {
+		return
+	}
+
+	return converted.Elem(), true, nil
+}
+
+func isSafeConversion(value reflect.Value, t reflect.Type) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return t.Kind() != reflect.String
+	case reflect.Float32, reflect.Float64:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return value.Convert(t).Convert(value.Type()).Float() == value.Float()
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,69 @@
+package gogetter
+
+import (
+	"errors"
+	"labix.org/v2/mgo/bson"
+	. "launchpad.net/gocheck"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type Role string
+
+type Cents int64
+
+type Account struct {
+	Id       bson.ObjectId
+	Role     Role
+	Count    int64
+	Ratio    *float64
+	Balance  Cents
+	OpenedAt time.Time
+	Timeout  time.Duration
+}
+
+func init() {
+	SetGoal("Account", func() Dream { return Account{} })
+	RegisterConverter(reflect.TypeOf(Cents(0)), func(v Dream) (Dream, error) {
+		if s, ok := v.(string); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			return Cents(f * 100), err
+		}
+		return Cents(reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0))).Float() * 100), nil
+	})
+}
+
+func (s *GoGetterSuite) TestConversion(c *C) {
+	gg := NewGoGetter(nil)
+	_, err := gg.Grow("Account", Lesson{"Count": 1})
+	c.Check(errors.As(err, new(*TypeMismatchError)), Equals, true)
+
+	gg.SetConversion(true)
+	id := bson.NewObjectId()
+	accountI, err := gg.Grow("Account", Lesson{
+		"Id":       id.Hex(),
+		"Role":     "admin",
+		"Count":    1,
+		"Ratio":    0.5,
+		"Balance":  "1.5",
+		"OpenedAt": "2014-01-06",
+		"Timeout":  "1m",
+	})
+	c.Check(err, Equals, nil)
+	account := accountI.(Account)
+	c.Check(account.Id, Equals, id)
+	c.Check(account.Role, Equals, Role("admin"))
+	c.Check(account.Count, Equals, int64(1))
+	c.Check(*account.Ratio, Equals, 0.5)
+	c.Check(account.Balance, Equals, Cents(150))
+	c.Check(account.OpenedAt.Equal(time.Date(2014, 1, 6, 0, 0, 0, 0, time.UTC)), Equals, true)
+	c.Check(account.Timeout, Equals, time.Minute)
+
+	_, err = gg.Grow("Account", Lesson{"Count": 1.5})
+	c.Check(errors.As(err, new(*TypeMismatchError)), Equals, true)
+	_, err = gg.Grow("Account", Lesson{"Role": 1})
+	c.Check(errors.As(err, new(*TypeMismatchError)), Equals, true)
+	_, err = gg.Grow("Account", Lesson{"OpenedAt": "yesterday"})
+	c.Check(err, Not(Equals), nil)
+}
@@ -3,12 +3,12 @@
 //
 // Supported Database
 //
-// 1. Mongo (Using labix.org/v2/mgo)
+// 1. Mongo (Using labix.org/v2/mgo, see mgodriver)
 //
-// <under construction>
+// 2. MySql, Postgres and SQLite (Using database/sql, see sqldriver)
 //
-// 2. MySql (Using github.com/coopernurse/gorp)
+// 3. MySql (Using github.com/eaigner/hood, see hooddriver)
 //
-// 3. Postgres (Using github.com/coopernurse/gorp)
+// 4. In memory, for unit tests without any database server (see memdriver)
 //
 package gogetter
@@ -0,0 +1,184 @@
+// Package drivertest is a conformance test suite for gogetter Databases. It
+// runs the same Create, Remove and cleanup scenarios against any Database, so
+// that all drivers agree on their semantics.
+//
+// Usage, in the gocheck tests of a driver:
+//
+// 	var _ = Suite(&drivertest.Suite{New: func(c *C) *drivertest.Fixture {
+// 		db := NewMyDb(...) // With an empty drivertest.Table
+// 		return &drivertest.Fixture{
+// 			Db:    db,
+// 			Count: func(table string) (int, error) { return db.Count(table) },
+// 		}
+// 	}})
+//
+// Scenarios of truncation, rollback and storing are skipped for Databases which
+// are not Truncaters, Transactional or Storers.
+package drivertest
+
+import (
+	"github.com/bom-d-van/gogetter"
+	"launchpad.net/gocheck"
+)
+
+// Table is the table records are created in. Drivers on top of schemas must
+// create it with the columns of Record: id (an integer primary key, which is
+// not auto-incremented), code and name (strings).
+const Table = "drivertest_records"
+
+// Record is the type of records created by Suite.
+type Record struct {
+	Id   int64  `bson:"_id" db:"id"`
+	Code string `bson:"code" db:"code"`
+	Name string `bson:"name" db:"name"`
+}
+
+// Fixture is a Database under test, along with what Suite needs to check it.
+type Fixture struct {
+	Db gogetter.Database
+
+	// Count returns the number of records in table, it's called out of any
+	// transaction of Db.
+	Count func(table string) (int, error)
+
+	// Close is called after each test, if it's not nil.
+	Close func()
+}
+
+// Suite is a gocheck suite, New is called before each test for a Fixture
+// with an empty Table, it could call c.Skip if the database is not available.
+type Suite struct {
+	New func(c *gocheck.C) *Fixture
+
+	fixture *Fixture
+}
+
+func (s *Suite) SetUpTest(c *gocheck.C) {
+	s.fixture = s.New(c)
+}
+
+func (s *Suite) TearDownTest(c *gocheck.C) {
+	if s.fixture != nil && s.fixture.Close != nil {
+		s.fixture.Close()
+	}
+	s.fixture = nil
+}
+
+func (s *Suite) count(c *gocheck.C) int {
+	n, err := s.fixture.Count(Table)
+	c.Assert(err, gocheck.Equals, nil)
+	return n
+}
+
+func (s *Suite) create(c *gocheck.C) {
+	err := s.fixture.Db.Create(Table, Record{1, "a", "Van"}, &Record{2, "b", "Gogh"}, Record{3, "c", "Vincent"})
+	c.Assert(err, gocheck.Equals, nil)
+	c.Assert(s.count(c), gocheck.Equals, 3)
+}
+
+func (s *Suite) registry() *gogetter.Registry {
+	reg := gogetter.NewRegistry(nil)
+	reg.SetGoal("Record", func() gogetter.Dream { return Record{Name: "Van"} })
+	reg.SetTableName("Record", Table)
+	reg.SetSequence("Record", "Id", "")
+	reg.SetSequence("Record", "Code", "code-%d")
+	return reg
+}
+
+func (s *Suite) TestCreate(c *gocheck.C) {
+	s.create(c)
+}
+
+func (s *Suite) TestCreateNothing(c *gocheck.C) {
+	c.Check(s.fixture.Db.Create(Table), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 0)
+}
+
+// Storers create records like Create, apart from writing back what's generated.
+func (s *Suite) TestStore(c *gocheck.C) {
+	storer, ok := s.fixture.Db.(gogetter.Storer)
+	if !ok {
+		c.Skip("not a Storer")
+	}
+	record := &Record{1, "a", "Van"}
+	c.Check(storer.Store(Table, record, &Record{2, "b", "Gogh"}), gocheck.Equals, nil)
+	c.Check(*record, gocheck.Equals, Record{1, "a", "Van"})
+	c.Check(s.count(c), gocheck.Equals, 2)
+	c.Check(s.fixture.Db.Remove(Table, "Id", int64(1), int64(2)), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 0)
+}
+
+func (s *Suite) TestRemove(c *gocheck.C) {
+	s.create(c)
+	c.Check(s.fixture.Db.Remove(Table, "Id", int64(1), int64(3)), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 1)
+}
+
+func (s *Suite) TestRemoveNothing(c *gocheck.C) {
+	s.create(c)
+	c.Check(s.fixture.Db.Remove(Table, "Id"), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 3)
+}
+
+func (s *Suite) TestRemoveUnknownIds(c *gocheck.C) {
+	s.create(c)
+	c.Check(s.fixture.Db.Remove(Table, "Id", int64(4), int64(5)), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 3)
+}
+
+// Id fields are passed to Remove by their Go field names.
+func (s *Suite) TestRemoveByIdField(c *gocheck.C) {
+	s.create(c)
+	c.Check(s.fixture.Db.Remove(Table, "Code", "a", "b"), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 1)
+}
+
+func (s *Suite) TestApocalypse(c *gocheck.C) {
+	gg := gogetter.NewGoGetter(s.fixture.Db, s.registry())
+	_, err := gg.Realize("Record", nil, nil)
+	c.Assert(err, gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 2)
+	c.Check(gg.Apocalypse(), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 0)
+}
+
+func (s *Suite) TestApocalypseByIdField(c *gocheck.C) {
+	reg := s.registry()
+	reg.SetDefaultTableId("Code")
+	gg := gogetter.NewGoGetter(s.fixture.Db, reg)
+	_, err := gg.Realize("Record", nil, nil)
+	c.Assert(err, gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 2)
+	c.Check(gg.Apocalypse(), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 0)
+}
+
+func (s *Suite) TestTruncate(c *gocheck.C) {
+	truncater, ok := s.fixture.Db.(gogetter.Truncater)
+	if !ok {
+		c.Skip("not a Truncater")
+	}
+	s.create(c)
+	c.Check(truncater.Truncate(Table), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 0)
+
+	gg := gogetter.NewGoGetter(s.fixture.Db, s.registry())
+	gg.SetCleanupStrategy(gogetter.CleanupByTruncation)
+	_, err := gg.Realize("Record")
+	c.Assert(err, gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 1)
+	c.Check(gg.Apocalypse(), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 0)
+}
+
+func (s *Suite) TestRollback(c *gocheck.C) {
+	if _, ok := s.fixture.Db.(gogetter.Transactional); !ok {
+		c.Skip("not Transactional")
+	}
+	gg := gogetter.NewGoGetter(s.fixture.Db, s.registry())
+	gg.SetCleanupStrategy(gogetter.CleanupByRollback)
+	_, err := gg.Realize("Record", nil, nil)
+	c.Assert(err, gocheck.Equals, nil)
+	c.Check(gg.Close(), gocheck.Equals, nil)
+	c.Check(s.count(c), gocheck.Equals, 0)
+}
@@ -0,0 +1,174 @@
+package gogetter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// LessonError is returned by Grow/Realize when a Lesson can't be applied to a
+// dream. Err is one of *UnknownFieldError, *TypeMismatchError and
+// *UnexportedFieldError, or an error about an invalid field path, all of them
+// could be retrieved with errors.As.
+type LessonError struct {
+	Goal string
+	// Index of the Lesson passed to Grow/Realize, -1 means the Lesson comes
+	// from SetTrait, AscendGoal or SetSequence.
+	Lesson int
+	Path   string
+	Err    error
+}
+
+func (e *LessonError) Error() string {
+	if e.Lesson < 0 {
+		return fmt.Sprintf("gogetter: %s of goal %q: %s", e.Path, e.Goal, e.Err)
+	}
+
+	return fmt.Sprintf("gogetter: %s in Lesson %d of goal %q: %s", e.Path, e.Lesson, e.Goal, e.Err)
+}
+
+func (e *LessonError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownFieldError means a field in the path doesn't exist in Struct.
+// Suggestion is the most similar field name, if there is one.
+type UnknownFieldError struct {
+	Struct     reflect.Type
+	Field      string
+	Suggestion string
+}
+
+func (e *UnknownFieldError) Error() string {
+	msg := fmt.Sprintf("unknown field %s in %s", e.Field, e.Struct)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %s?", e.Suggestion)
+	}
+
+	return msg
+}
+
+// TypeMismatchError means a Lesson value of type Actual can't be assigned to
+// a field of type Expected.
+type TypeMismatchError struct {
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("can't use %s as %s", e.Actual, e.Expected)
+}
+
+// UnexportedFieldError means a field in the path is unexported, so gogetter
+// can't set it.
+type UnexportedFieldError struct {
+	Struct reflect.Type
+	Field  string
+}
+
+func (e *UnexportedFieldError) Error() string {
+	return fmt.Sprintf("field %s of %s is unexported", e.Field, e.Struct)
+}
+
+// suggestField finds the field name, or the name in tag, that is most similar
+// to name. Nothing is returned if none of them looks similar enough.
+func suggestField(t reflect.Type, name, tag string) (suggestion string) {
+	candidates := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		candidates = append(candidates, field.Name)
+		if tag == "" {
+			continue
+		}
+		if tagName := strings.Split(field.Tag.Get(tag), ",")[0]; tagName != "" && tagName != "-" {
+			candidates = append(candidates, tagName)
+		}
+	}
+
+	best := len(name)/3 + 2
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, name) {
+			return candidate
+		}
+		if d := editDistance(strings.ToLower(candidate), strings.ToLower(name)); d < best {
+			best = d
+			suggestion = candidate
+		}
+	}
+
+	return
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// CleanupError is a failure of destroying the dreams of Goal in Table. Goal and
+// Table are empty if it's a failure of rolling back, see CleanupByRollback.
+type CleanupError struct {
+	Goal  string
+	Table string
+	Err   error
+}
+
+func (e *CleanupError) Error() string {
+	if e.Goal == "" {
+		return fmt.Sprintf("gogetter: failed to roll back: %s", e.Err)
+	}
+	return fmt.Sprintf("gogetter: failed to destroy %q in table %q: %s", e.Goal, e.Table, e.Err)
+}
+
+func (e *CleanupError) Unwrap() error {
+	return e.Err
+}
+
+// ApocalypseError is returned by Apocalypse, listing every failed goal in the
+// order of failures. errors.Is and errors.As check all of them.
+type ApocalypseError struct {
+	Errors []*CleanupError
+}
+
+func (e *ApocalypseError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (e *ApocalypseError) Unwrap() []error {
+	errs := []error{}
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
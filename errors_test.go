@@ -0,0 +1,61 @@
+package gogetter
+
+import (
+	"errors"
+	. "launchpad.net/gocheck"
+	"reflect"
+)
+
+type Secret struct {
+	Name     string
+	password string
+}
+
+func init() {
+	SetGoal("Secret", func() Dream { return Secret{} })
+}
+
+func (s *GoGetterSuite) TestUnknownFieldError(c *C) {
+	_, err := Grow("User", nil, Lesson{"Dream.Titel": "Title"})
+	lessonErr := &LessonError{}
+	c.Assert(errors.As(err, &lessonErr), Equals, true)
+	c.Check(lessonErr.Goal, Equals, "User")
+	c.Check(lessonErr.Lesson, Equals, 1)
+	c.Check(lessonErr.Path, Equals, "Dream.Titel")
+
+	unknownErr := &UnknownFieldError{}
+	c.Assert(errors.As(err, &unknownErr), Equals, true)
+	c.Check(unknownErr.Struct, Equals, reflect.TypeOf(DreamS{}))
+	c.Check(unknownErr.Field, Equals, "Titel")
+	c.Check(unknownErr.Suggestion, Equals, "Title")
+	c.Check(err.Error(), Equals, `gogetter: Dream.Titel in Lesson 1 of goal "User": unknown field Titel in gogetter.DreamS, did you mean Title?`)
+
+	_, err = Grow("User", Lesson{"name": "Name"})
+	c.Assert(errors.As(err, &unknownErr), Equals, true)
+	c.Check(unknownErr.Suggestion, Equals, "Name")
+
+	_, err = Grow("User", Lesson{"Whatever": "Name"})
+	c.Assert(errors.As(err, &unknownErr), Equals, true)
+	c.Check(unknownErr.Suggestion, Equals, "")
+}
+
+func (s *GoGetterSuite) TestTypeMismatchError(c *C) {
+	_, err := Grow("User", Lesson{"Name": 1})
+	mismatchErr := &TypeMismatchError{}
+	c.Assert(errors.As(err, &mismatchErr), Equals, true)
+	c.Check(mismatchErr.Expected, Equals, reflect.TypeOf(""))
+	c.Check(mismatchErr.Actual, Equals, reflect.TypeOf(1))
+
+	_, err = Grow("User", Lesson{"Dream": Sequence("")})
+	c.Check(errors.As(err, &mismatchErr), Equals, true)
+
+	_, err = Grow("User", Lesson{"Name": Inspiration(func(Dream, int) Dream { return 1 })})
+	c.Check(errors.As(err, &mismatchErr), Equals, true)
+}
+
+func (s *GoGetterSuite) TestUnexportedFieldError(c *C) {
+	_, err := Grow("Secret", Lesson{"password": "123456"})
+	unexportedErr := &UnexportedFieldError{}
+	c.Assert(errors.As(err, &unexportedErr), Equals, true)
+	c.Check(unexportedErr.Field, Equals, "password")
+}
@@ -1,8 +1,7 @@
 package gogetter
 
 import (
-	"github.com/bom-d-van/gogetter/mgodriver"
-	"labix.org/v2/mgo"
+	"github.com/bom-d-van/gogetter/memdriver"
 	"labix.org/v2/mgo/bson"
 	. "launchpad.net/gocheck"
 )
@@ -11,13 +10,14 @@ type GetterDbSuite struct {
 	users   []User
 	pusers  []*User
 	ppusers []**User
-	db      *mgo.Database
+	db      *memdriver.MemDb
 }
 
 var _ = Suite(&GetterDbSuite{})
 
 func init() {
 	SetGoal("PUser", func() Dream { return makePUser() })
+	SetIdGenerator("PUser", func() Dream { return bson.NewObjectId() })
 }
 
 func makePUser() *User {
@@ -30,16 +30,16 @@ func makePUser() *User {
 }
 
 func (s *GetterDbSuite) SetUpSuite(c *C) {
-	s.db = getTestDb()
-	SetDefaultGetterDb(mgodriver.NewMongoDb(s.db))
+	s.db = memdriver.NewMemDb()
+	SetDefaultGetterDb(s.db)
 }
 
-func getTestDb() *mgo.Database {
-	session, err := mgo.Dial("localhost")
-	if err != nil {
-		panic(err)
+// countIds counts records in table with the ids.
+func countIds(db *memdriver.MemDb, table string, ids ...bson.ObjectId) (count int) {
+	for _, id := range ids {
+		count += len(db.Find(table, "Id", id))
 	}
-	return session.DB("gogetter")
+	return
 }
 
 func (s *GetterDbSuite) SetUpTest(c *C) {
@@ -56,34 +56,28 @@ func (s *GetterDbSuite) SetUpTest(c *C) {
 	})
 	c.Check(err, Equals, nil)
 	s.users = usersI.([]User)
-	count, err := s.db.C("users").FindId(bson.M{"$in": []bson.ObjectId{s.users[0].Id, s.users[1].Id}}).Count()
-	c.Check(err, Equals, nil)
+	count := countIds(s.db, "users", s.users[0].Id, s.users[1].Id)
 	c.Check(count, Equals, 2)
 
 	defaultGetter.dreams["PUser"] = nil
-	pointerUserI, err := Realize("PUser", Lesson{"Id": bson.NewObjectId()}, Lesson{"Id": bson.NewObjectId()})
+	pointerUserI, err := Realize("PUser", nil, nil)
 	c.Check(err, Equals, nil)
 	s.pusers = pointerUserI.([]*User)
-	count, err = s.db.C("pusers").FindId(bson.M{"$in": []bson.ObjectId{s.pusers[0].Id}}).Count()
-	c.Check(err, Equals, nil)
-	c.Check(count, Equals, 1)
+	c.Check(s.pusers[0].Id.Valid(), Equals, true)
+	count = countIds(s.db, "pusers", s.pusers[0].Id, s.pusers[1].Id)
+	c.Check(count, Equals, 2)
 
 	defaultGetter.dreams["Pointer User"] = nil
 	ppuserI, err := Realize("*Pointer User", Lesson{"Id": bson.NewObjectId()}, Lesson{"Id": bson.NewObjectId()})
 	c.Check(err, Equals, nil)
 	s.ppusers = ppuserI.([]**User)
-	count, err = s.db.C("pointer_users").FindId(bson.M{"$in": []bson.ObjectId{(*s.ppusers[0]).Id, (*s.ppusers[1]).Id}}).Count()
-	c.Check(err, Equals, nil)
+	count = countIds(s.db, "pointer_users", (*s.ppusers[0]).Id, (*s.ppusers[1]).Id)
 	c.Check(count, Equals, 2)
 }
 
 func (s *GetterDbSuite) TearDownTest(c *C) {
-	_, err := s.db.C("users").RemoveAll(nil)
-	c.Check(err, Equals, nil)
-	_, err = s.db.C("pusers").RemoveAll(nil)
+	err := s.db.Truncate("users", "pusers", "pointer_users")
 	c.Check(err, Equals, nil)
-	// _, err = s.db.C("pointer_users").RemoveAll(nil)
-	// c.Check(err, Equals, nil)
 }
 
 func (s *GetterDbSuite) TestCommonAllInVain(c *C) {
@@ -91,8 +85,7 @@ func (s *GetterDbSuite) TestCommonAllInVain(c *C) {
 	err := AllInVain("User", s.users[0])
 	c.Check(err, Equals, nil)
 	c.Check(defaultGetter.dreams["User"], HasLen, 1)
-	count, err := s.db.C("users").FindId(bson.M{"$in": []bson.ObjectId{s.users[0].Id, s.users[1].Id}}).Count()
-	c.Check(err, Equals, nil)
+	count := countIds(s.db, "users", s.users[0].Id, s.users[1].Id)
 	c.Check(count, Equals, 1)
 }
 
@@ -101,8 +94,7 @@ func (s *GetterDbSuite) TestAllInVainReceivingSlice(c *C) {
 	err := AllInVain("User", s.users)
 	c.Check(err, Equals, nil)
 	c.Check(defaultGetter.dreams["User"], HasLen, 0)
-	count, err := s.db.C("users").FindId(bson.M{"$in": []bson.ObjectId{s.users[0].Id, s.users[1].Id}}).Count()
-	c.Check(err, Equals, nil)
+	count := countIds(s.db, "users", s.users[0].Id, s.users[1].Id)
 	c.Check(count, Equals, 0)
 }
 
@@ -111,8 +103,7 @@ func (s *GetterDbSuite) TestAllInVainWithZeroArguments(c *C) {
 	err := AllInVain("User")
 	c.Check(err, Equals, nil)
 	c.Check(defaultGetter.dreams["User"], HasLen, 0)
-	count, err := s.db.C("users").FindId(bson.M{"$in": []bson.ObjectId{s.users[0].Id, s.users[1].Id}}).Count()
-	c.Check(err, Equals, nil)
+	count := countIds(s.db, "users", s.users[0].Id, s.users[1].Id)
 	c.Check(count, Equals, 0)
 }
 
@@ -121,8 +112,7 @@ func (s *GetterDbSuite) TestPointerAllInVain(c *C) {
 	err := AllInVain("PUser", s.pusers[0])
 	c.Check(err, Equals, nil)
 	c.Check(defaultGetter.dreams["PUser"], HasLen, 1)
-	count, err := s.db.C("pusers").FindId(bson.M{"$in": []bson.ObjectId{s.pusers[0].Id}}).Count()
-	c.Check(err, Equals, nil)
+	count := countIds(s.db, "pusers", s.pusers[0].Id)
 	c.Check(count, Equals, 0)
 }
 
@@ -131,8 +121,7 @@ func (s *GetterDbSuite) TestDoublePointerAllInVain(c *C) {
 	err := AllInVain("Pointer User", s.ppusers[0])
 	c.Check(err, Equals, nil)
 	c.Check(defaultGetter.dreams["Pointer User"], HasLen, 1)
-	count, err := s.db.C("pointer_users").FindId(bson.M{"$in": []bson.ObjectId{(*s.ppusers[0]).Id}}).Count()
-	c.Check(err, Equals, nil)
+	count := countIds(s.db, "pointer_users", (*s.ppusers[0]).Id)
 	c.Check(count, Equals, 0)
 }
 
@@ -166,8 +155,7 @@ func (s *GetterDbSuite) TestAscendGoals(c *C) {
 	superUsersI, err := Realize("*Super User", Lesson{"Name": "Super 1"}, Lesson{"Name": "Super 2"})
 	c.Check(err, Equals, nil)
 	superUsers := superUsersI.([]*User)
-	count, err := s.db.C("users").FindId(bson.M{"$in": []bson.ObjectId{superUsers[0].Id, superUsers[1].Id}}).Count()
-	c.Check(err, Equals, nil)
+	count := countIds(s.db, "users", superUsers[0].Id, superUsers[1].Id)
 	c.Check(count, Equals, 2)
 
 	c.Check(defaultGetter.dreams["Super User"], HasLen, 2)
@@ -178,8 +166,7 @@ func (s *GetterDbSuite) TestAscendGoals(c *C) {
 	Apocalypse("Super User")
 
 	c.Check(defaultGetter.dreams["Super User"], HasLen, 0)
-	count, err = s.db.C("users").FindId(bson.M{"$in": []bson.ObjectId{superUsers[0].Id, superUsers[1].Id}}).Count()
-	c.Check(err, Equals, nil)
+	count = countIds(s.db, "users", superUsers[0].Id, superUsers[1].Id)
 	c.Check(count, Equals, 0)
 }
 
@@ -1,10 +1,10 @@
 package gogetter
 
 import (
-	"bitbucket.org/pkg/inflect"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -13,100 +13,166 @@ type Dream interface{}
 type Goal func() Dream
 type Lesson map[string]Dream
 
-// TODO: Support Special Type
-// type Inspiration func() Dream
-
+// Inspiration is a special Lesson value, which is evaluated for each spawned dream
+// instead of being set verbatim. It receives a pointer to the partially built
+// dream, in which all the other Lesson values are already set, and the index of
+// the dream in the batch of Grow/Realize.
+//
+// Usage:
+//
+// 	gogetter.Grow("User", gogetter.Lesson{
+// 		"Name": "Van",
+// 		"Email": gogetter.Inspiration(func(dream gogetter.Dream, index int) gogetter.Dream {
+// 			return fmt.Sprintf("%s-%d@example.com", dream.(*User).Name, index)
+// 		}),
+// 	})
+type Inspiration func(dream Dream, index int) Dream
+
+// Database creates and removes records of dreams. Create receives dreams as
+// they are, i.e. copies for value goals and pointers for pointer goals, see
+// Storer for writing generated values back to dreams of both.
 type Database interface {
 	Create(table string, data ...interface{}) (err error)
 	Remove(table string, idField string, ids ...interface{}) (err error)
 }
 
+// Storer is implemented by Databases generating values on creation, e.g.
+// auto-increment ids, server-side defaults or ObjectIds of mongo. Store is
+// called instead of Create, with pointers to the dreams, which it should update
+// to their stored versions. Then the dreams returned by Realize, passed to
+// AfterCreate hooks and destroyed by Apocalypse are the stored ones.
+type Storer interface {
+	Store(table string, records ...interface{}) error
+}
+
+// GoGetter is safe for concurrent use, goals could be grown, realized and
+// destroyed by parallel tests sharing the same GoGetter.
 type GoGetter struct {
-	dreams map[string][]Dream
-	db     Database
+	// mutex guards dreams, db, creations, options and children
+	mutex    sync.Mutex
+	dreams   map[string][]Dream
+	db       Database
+	registry *Registry
+
+	// sequences might be shared with GoGetters spawned by the GoGetter
+	sequences *sequenceCounters
+
+	// creations records the order in which goals are firstly created
+	creations map[string]int
+	options   getterOptions
+
+	// parent and children link scopes, see (gg *GoGetter) Scope
+	parent   *GoGetter
+	children []*GoGetter
+
+	// tx is the transaction begun for CleanupByRollback, adoptedTxs are the
+	// ones of adopted GoGetters which are not its scopes
+	tx         Transaction
+	adoptedTxs []Transaction
+	txMutex    sync.Mutex
 }
 
-func NewGoGetter(db Database) *GoGetter {
+type getterOptions struct {
+	lessonTag          string
+	conversion         bool
+	cleanupOrder       CleanupOrder
+	stopOnCleanupError bool
+	cleanupStrategy    CleanupStrategy
+}
+
+// NewGoGetter creates a GoGetter using goals in the registry, or the default
+// registry if it's not provided.
+func NewGoGetter(db Database, registry ...*Registry) *GoGetter {
+	reg := defaultRegistry
+	if len(registry) > 0 && registry[0] != nil {
+		reg = registry[0]
+	}
+
 	return &GoGetter{
-		db:     db,
-		dreams: map[string][]Dream{},
+		db:        db,
+		registry:  reg,
+		dreams:    map[string][]Dream{},
+		sequences: &sequenceCounters{values: map[sequenceKey]int{}},
+		creations: map[string]int{},
 	}
 }
 
-var ErrGetterNotExist = errors.New("Getter Not Exist")
-var defaultGetter = NewGoGetter(nil)
-var goalMap = map[string]Goal{}
-var tableNameMap = map[string]string{}
+func (gg *GoGetter) getOptions() getterOptions {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
 
-// Setting table name is optional, if table name is not specifically setted, gogetter
-// will use the pluralization and lower case form of the name as table name, it will
-// also replace all spaces with underscores.
-func SetTableName(name, table string) {
-	tableNameMap[name] = table
+	return gg.options
 }
 
-func GetTableName(name string) (table string, err error) {
-	var ok bool
-	table, ok = tableNameMap[name]
-	if ok {
-		return
-	}
+// Registry returns the registry of goals used by the GoGetter.
+func (gg *GoGetter) Registry() *Registry {
+	return gg.registry
+}
 
-	if GetGoal(name) == nil {
-		return "", ErrGetterNotExist
-	}
+func (gg *GoGetter) getDb() Database {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
 
-	if pg, yes := parentGoalMap[name]; yes {
-		table, err = GetTableName(pg.parent)
-	} else {
-		table = inflect.Pluralize(strings.ToLower(name))
-		table = strings.Replace(table, " ", "_", -1)
-	}
+	return gg.db
+}
 
-	tableNameMap[name] = table
+var ErrGetterNotExist = errors.New("Getter Not Exist")
+var defaultGetter = NewGoGetter(nil)
 
-	return
+// See (r *Registry) SetTableName.
+func SetTableName(name, table string) {
+	defaultRegistry.SetTableName(name, table)
 }
 
-// var mux = sync.Mutex{}
+// See (r *Registry) GetTableName.
+func GetTableName(name string) (table string, err error) {
+	return defaultRegistry.GetTableName(name)
+}
 
-// SetGoal will save the Goal globally, then all gogetter values could share
-// the same set of goals.
-//
-// 	Note:
-// 	1. Leading asterisk (*) in name is saved for gogetter.
-// 	2. The return value of goal must be a Struct, map or anything else is not supported.
+// See (r *Registry) SetGoal.
 func SetGoal(name string, goal Goal) {
-	// mux.Lock()
-	// defer mux.Unlock()
-
-	goalMap[name] = goal
+	defaultRegistry.SetGoal(name, goal)
 }
 
+// See (r *Registry) GetGoal.
 func GetGoal(name string) Goal {
-	goal, ok := goalMap[name]
-	if !ok {
-		return nil
-	}
-
-	return goal
+	return defaultRegistry.GetGoal(name)
 }
 
 func SetDefaultGetterDb(db Database) {
+	defaultGetter.mutex.Lock()
+	defer defaultGetter.mutex.Unlock()
+
 	defaultGetter.db = db
 }
 
+// See (gg *GoGetter) SetLessonTag.
+func SetLessonTag(tag string) {
+	defaultGetter.SetLessonTag(tag)
+}
+
+// By default, Lesson keys are Go field names. SetLessonTag makes gogetter look
+// up fields by their names in the struct tag first, e.g. "bson", "json" or "db",
+// then fall back to Go names, so Lessons could be written from JSON fixtures or
+// Mongo documents directly. An empty tag restores the default behaviour.
+//
+// 	gogetter.SetLessonTag("bson")
+// 	gogetter.Grow("User", gogetter.Lesson{"_id": id, "first_name": "Van"})
+func (gg *GoGetter) SetLessonTag(tag string) {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
+
+	gg.options.lessonTag = tag
+}
+
 type parentGoal struct {
 	parent string
 	lesson func() Lesson
 }
 
-var parentGoalMap = map[string]*parentGoal{}
-
-// By default, GetTableName will use parent's table name.
+// See (r *Registry) AscendGoal.
 func AscendGoal(child, parent string, lesson func() Lesson) {
-	SetGoal(child, goalMap[parent])
-	parentGoalMap[child] = &parentGoal{parent, lesson}
+	defaultRegistry.AscendGoal(child, parent, lesson)
 }
 
 // See (gg *GoGetter) Grow.
@@ -124,7 +190,8 @@ func AllInVain(name string, dreams ...Dream) (err error) {
 	return defaultGetter.AllInVain(name, dreams...)
 }
 
-// Could use a leading asterisk (*) in name to get pointer value.
+// Could use a leading asterisk (*) in name to get pointer value, and traits
+// separated by commas after a colon (:) in name to apply traits, see SetTrait.
 //
 // 	TODO:
 // 	1. Support anonymous type, e,g, custom struct, map, etc
@@ -134,8 +201,9 @@ func (gg *GoGetter) Grow(name string, lessons ...Lesson) (dreams Dream, err erro
 }
 
 type spawnChan struct {
-	goal reflect.Value
-	err  error
+	index int
+	goal  reflect.Value
+	err   error
 }
 
 func (gg *GoGetter) makeDreams(name string, saveInDb bool, lessons ...Lesson) (dreams Dream, err error) {
@@ -149,10 +217,16 @@ func (gg *GoGetter) makeDreams(name string, saveInDb bool, lessons ...Lesson) (d
 	if inPointer {
 		name = name[1:]
 	}
-	goal := GetGoal(name)
+	name, traits := gg.registry.parseTraits(name)
+	goal := gg.registry.GetGoal(name)
 	if goal == nil {
 		return nil, ErrGetterNotExist
 	}
+	for _, trait := range traits {
+		if gg.registry.getTrait(name, trait) == nil {
+			return nil, fmt.Errorf("gogetter: trait %q of goal %q not exist", trait, name)
+		}
+	}
 
 	// Start Produce Dreams
 	firstD := reflect.ValueOf(goal())
@@ -164,33 +238,62 @@ func (gg *GoGetter) makeDreams(name string, saveInDb bool, lessons ...Lesson) (d
 	if len(lessons) == 0 {
 		lessons = append(lessons, nil)
 	}
+	assocs := gg.registry.getAssociations(name)
+	lessons, strategies := takeStrategies(assocs, lessons, saveInDb)
 
-	go gg.spawnNewDream(lessons[0], firstD, dType, inPointer, name, ch)
+	go gg.spawnNewDream(lessons[0], 0, firstD, dType, inPointer, name, traits, ch)
 
 	for i, _ := range lessons[1:] {
-		go gg.spawnNewDreamRaw(lessons[i+1], goal, dType, inPointer, name, ch)
+		go gg.spawnNewDreamRaw(lessons[i+1], i+1, goal, dType, inPointer, name, traits, ch)
 	}
 
 	// Receive Dreams
 	// TODO: Could be replaced by a simple interface{}?
-	goals := reflect.MakeSlice(reflect.SliceOf(dType), 0, 0)
+	// Dreams are spawned concurrently, but placed in the same order of lessons.
+	eggs := make([]spawnChan, len(lessons))
 	for i := 0; i < len(lessons); i++ {
 		egg := <-ch
+		eggs[egg.index] = egg
+	}
+	goals := reflect.MakeSlice(reflect.SliceOf(dType), 0, len(lessons))
+	for _, egg := range eggs {
 		if egg.err != nil {
 			err = egg.err
 			return
 		}
 		goals = reflect.Append(goals, egg.goal)
-		gg.dreams[name] = append(gg.dreams[name], egg.goal.Interface())
+	}
+	if err = gg.makeAssociations(name, assocs, goals, strategies, belongsTo); err != nil {
+		return
+	}
+	if err = gg.registry.runHooks(name, afterBuild, goals); err != nil {
+		return
 	}
 
-	if saveInDb && gg.db != nil {
-		err = gg.createRecords(name, goals)
-		if err != nil {
-			return
+	if saveInDb {
+		var db Database
+		if db, err = gg.Db(); err == nil && db != nil {
+			err = gg.createRecords(db, name, goals)
 		}
 	}
 
+	// Dreams are tracked after being created, so they reflect the changes made
+	// by hooks, and even if creation is failed, for cleanup.
+	gg.mutex.Lock()
+	for i := 0; i < goals.Len(); i++ {
+		gg.dreams[name] = append(gg.dreams[name], goals.Index(i).Interface())
+	}
+	if _, ok := gg.creations[name]; !ok {
+		gg.creations[name] = len(gg.creations)
+	}
+	gg.mutex.Unlock()
+	if err != nil {
+		return
+	}
+	if err = gg.makeAssociations(name, assocs, goals, strategies, hasOne, hasMany); err != nil {
+		return
+	}
+
 	// Return userful/handy results
 	if goals.Len() == 0 {
 		dreams = reflect.Zero(dType).Interface()
@@ -203,35 +306,53 @@ func (gg *GoGetter) makeDreams(name string, saveInDb bool, lessons ...Lesson) (d
 	return
 }
 
-func (gg *GoGetter) createRecords(name string, goals reflect.Value) (err error) {
+func (gg *GoGetter) createRecords(db Database, name string, goals reflect.Value) (err error) {
 	table := ""
-	table, err = GetTableName(name)
+	table, err = gg.registry.GetTableName(name)
 	if err != nil {
 		return
 	}
 
+	if err = gg.registry.generateIds(name, goals); err != nil {
+		return
+	}
+	if err = gg.registry.runHooks(name, beforeCreate, goals); err != nil {
+		return
+	}
+
 	records := []interface{}{}
+	storer, ok := db.(Storer)
 	for i := 0; i < goals.Len(); i++ {
-		records = append(records, goals.Index(i).Interface())
+		record := goals.Index(i)
+		if ok && record.Kind() != reflect.Ptr {
+			record = record.Addr()
+		}
+		records = append(records, record.Interface())
+	}
+	if ok {
+		err = storer.Store(table, records...)
+	} else {
+		err = db.Create(table, records...)
+	}
+	if err != nil {
+		return
 	}
-	err = gg.db.Create(table, records...)
-
-	// TODO: add after create tag support, for some data will only be assigned after saved in database
 
-	return
+	return gg.registry.runHooks(name, afterCreate, goals)
 }
 
-func (gg *GoGetter) spawnNewDreamRaw(lesson Lesson, goal Goal, dType reflect.Type, inPointer bool, name string, ch chan spawnChan) {
-	gg.spawnNewDream(lesson, reflect.ValueOf(goal()), dType, inPointer, name, ch)
+func (gg *GoGetter) spawnNewDreamRaw(lesson Lesson, index int, goal Goal, dType reflect.Type, inPointer bool, name string, traits []string, ch chan spawnChan) {
+	gg.spawnNewDream(lesson, index, reflect.ValueOf(goal()), dType, inPointer, name, traits, ch)
 }
 
-func (gg *GoGetter) spawnNewDream(lesson Lesson, forebear reflect.Value, dType reflect.Type, inPointer bool, name string, ch chan spawnChan) {
+func (gg *GoGetter) spawnNewDream(lesson Lesson, index int, forebear reflect.Value, dType reflect.Type, inPointer bool, name string, traits []string, ch chan spawnChan) {
 	// To Comment out for better debug information
 	defer func() {
 		if r := recover(); r != nil {
 			ch <- spawnChan{
-				goal: reflect.Zero(forebear.Type()),
-				err:  fmt.Errorf("%+v", r),
+				index: index,
+				goal:  reflect.Zero(forebear.Type()),
+				err:   fmt.Errorf("%+v", r),
 			}
 		}
 	}()
@@ -256,31 +377,182 @@ func (gg *GoGetter) spawnNewDream(lesson Lesson, forebear reflect.Value, dType r
 		}
 	}
 
-	for j := 0; j < src.NumField(); j++ {
-		fIndex := []int{j}
-		v := src.FieldByIndex(fIndex)
-		dst.FieldByIndex(fIndex).Set(v)
-	}
+	// Dreams must not share anything with the forebear, which might be reused by goals
+	deepCopy(dst, src)
 
 	lessons := []Lesson{lesson}
-	lessons = append(lessons, getParentLessons(name)...)
-	for i := len(lessons) - 1; i >= 0; i-- {
-		lesson := lessons[i]
-		for k, v := range lesson {
-			dst.FieldByName(k).Set(reflect.ValueOf(v))
+	lessons = append(lessons, gg.registry.getTraitLessons(name, traits)...)
+	lessons = append(lessons, gg.registry.getParentLessons(name)...)
+	lessons = append(lessons, gg.registry.getSequenceLesson(name))
+	if err := gg.learn(dst, lessons, name, index); err != nil {
+		ch <- spawnChan{
+			index: index,
+			goal:  reflect.Zero(forebear.Type()),
+			err:   err,
 		}
+		return
 	}
 
 	ch <- spawnChan{
-		goal: theone.Elem(),
-		err:  nil,
+		index: index,
+		goal:  theone.Elem(),
+		err:   nil,
 	}
 }
 
-func getParentLessons(name string) (lessons []Lesson) {
-	for {
-		pg, ok := parentGoalMap[name]
+// learn applies lessons to dst, from the last one to the first, so that the
+// first lesson takes precedence. Keys of a lesson are applied in sorted order,
+// which makes sure "Dream" is set before "Dream.Title".
+//
+// The first lesson is the one passed to Grow/Realize, its index in the batch is
+// index, the others come from SetTrait, AscendGoal and SetSequence.
+func (gg *GoGetter) learn(dst reflect.Value, lessons []Lesson, name string, index int) (err error) {
+	tag := gg.getOptions().lessonTag
+	lessonError := func(i int, path string, err error) error {
+		lessonIndex := -1
+		if i == 0 {
+			lessonIndex = index
+		}
+		return &LessonError{Goal: name, Lesson: lessonIndex, Path: path, Err: err}
+	}
+
+	type inspiration struct {
+		Inspiration
+		lesson int
+	}
+	inspirations := map[string]inspiration{}
+	for i := len(lessons) - 1; i >= 0; i-- {
+		keys := []string{}
+		for k, _ := range lessons[i] {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			// Inspirations of k, and of paths in k, of Lessons with lower
+			// precedence are overridden.
+			for path, _ := range inspirations {
+				if isSubPath(path, k) {
+					delete(inspirations, path)
+				}
+			}
+			switch v := lessons[i][k].(type) {
+			case Inspiration:
+				inspirations[k] = inspiration{v, i}
+			case func(Dream, int) Dream:
+				inspirations[k] = inspiration{v, i}
+			case Sequence:
+				// Counters don't advance for fields overridden by Lessons
+				// with higher precedence.
+				if isOverridden(lessons[:i], k) {
+					continue
+				}
+				err = setFieldPath(dst, k, tag, func(field reflect.Value) error {
+					return v.set(field, gg.Next(name, k))
+				})
+			default:
+				// Values are copied before paths in them are set, so that
+				// neither the Lesson value nor the other dreams change.
+				if hasSubPath(lessons[:i+1], k) {
+					v = copyDream(v)
+				}
+				err = setFieldPath(dst, k, tag, func(field reflect.Value) error {
+					return gg.assign(field, v)
+				})
+			}
+			if err != nil {
+				return lessonError(i, k, err)
+			}
+		}
+	}
+
+	// Inspirations are evaluated after all the other values are set, in a
+	// stable order, so that they could depend on them.
+	keys := []string{}
+	for k, _ := range inspirations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := inspirations[k].Inspiration(dst.Addr().Interface(), index)
+		err = setFieldPath(dst, k, tag, func(field reflect.Value) error {
+			return gg.assign(field, v)
+		})
+		if err != nil {
+			return lessonError(inspirations[k].lesson, k, err)
+		}
+	}
+
+	return
+}
+
+// isSubPath checks whether path is parent, or a path in parent, e.g.
+// "Dream.Title" or "Places[0]" in "Dream" or "Places".
+func isSubPath(path, parent string) bool {
+	if !strings.HasPrefix(path, parent) {
+		return false
+	}
+
+	return len(path) == len(parent) || path[len(parent)] == '.' || path[len(parent)] == '['
+}
+
+// isOverridden checks whether path, or a path containing it, is a key of lessons.
+func isOverridden(lessons []Lesson, path string) bool {
+	for _, lesson := range lessons {
+		for k, _ := range lesson {
+			if isSubPath(path, k) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasSubPath checks whether any key of lessons is a path in parent.
+func hasSubPath(lessons []Lesson, parent string) bool {
+	for _, lesson := range lessons {
+		for k, _ := range lesson {
+			if k != parent && isSubPath(k, parent) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// assign sets the Lesson value v to field, nil stands for the zero value.
+// Values are converted if it's enabled by SetConversion.
+func (gg *GoGetter) assign(field reflect.Value, v Dream) error {
+	if v == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	value := reflect.ValueOf(v)
+	if !value.Type().AssignableTo(field.Type()) {
+		if !gg.getOptions().conversion {
+			return &TypeMismatchError{Expected: field.Type(), Actual: value.Type()}
+		}
+		converted, ok, err := gg.registry.convert(value, field.Type())
+		if err != nil {
+			return err
+		}
 		if !ok {
+			return &TypeMismatchError{Expected: field.Type(), Actual: value.Type()}
+		}
+		value = converted
+	}
+	field.Set(value)
+
+	return nil
+}
+
+func (r *Registry) getParentLessons(name string) (lessons []Lesson) {
+	for {
+		pg := r.getParentGoal(name)
+		if pg == nil {
 			break
 		}
 		lessons = append(lessons, pg.lesson())
@@ -308,8 +580,6 @@ func (gg *GoGetter) Realize(name string, lessons ...Lesson) (dreams Dream, err e
 	return gg.makeDreams(name, true, lessons...)
 }
 
-var allInVainMutex = sync.Mutex{}
-
 // TODO: [AllInVain] enable field tag configuration
 
 //
@@ -322,9 +592,6 @@ var allInVainMutex = sync.Mutex{}
 // 	gogetter.AllInVain("users", users) // users is a slice of User
 //
 func (gg *GoGetter) AllInVain(name string, dreams ...Dream) (err error) {
-	allInVainMutex.Lock()
-	defer allInVainMutex.Unlock()
-
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("%+v", r)
@@ -339,19 +606,21 @@ func (gg *GoGetter) AllInVain(name string, dreams ...Dream) (err error) {
 		}
 	}
 
-	table, err := GetTableName(name)
+	table, err := gg.registry.GetTableName(name)
 	if err != nil {
 		return
 	}
 
+	gg.mutex.Lock()
 	if len(dreams) == 0 {
 		dreams = gg.dreams[name]
-		if len(dreams) == 0 {
-			return
-		}
+	}
+	gg.mutex.Unlock()
+	if len(dreams) == 0 {
+		return
 	}
 
-	idField := getDreamIdField(name)
+	idField := gg.registry.getDreamIdField(name, reflect.TypeOf(dreams[0]))
 	if idField == "" {
 		err = errors.New("Id Field is Not Exist")
 		return
@@ -362,6 +631,7 @@ func (gg *GoGetter) AllInVain(name string, dreams ...Dream) (err error) {
 		ids = append(ids, gg.retrieveDreamId(dreams[i], idField))
 	}
 
+	gg.mutex.Lock()
 	survivedDreams := []Dream{}
 	for _, dream := range gg.dreams[name] {
 		dreamId := gg.retrieveDreamId(dream, idField)
@@ -376,9 +646,10 @@ func (gg *GoGetter) AllInVain(name string, dreams ...Dream) (err error) {
 	hell:
 	}
 	gg.dreams[name] = survivedDreams
+	gg.mutex.Unlock()
 
-	if gg.db != nil {
-		err = gg.db.Remove(table, idField, ids...)
+	if db := gg.currentDb(); db != nil {
+		err = db.Remove(table, idField, ids...)
 	}
 
 	return
@@ -402,51 +673,9 @@ retriving:
 	return
 }
 
-var defaultTableId = "Id"
-
-// Table Id is used when gogetter is trying remove records from table, using a simple
-// sql/mongo statement to remove the data.
-// Default Table Id is "Id", its value must be comparable via reflect.DeepEqual.
+// See (r *Registry) SetDefaultTableId.
 func SetDefaultTableId(name string) {
-	defaultTableId = name
-}
-
-var dreamIdFieldMap = map[string]string{}
-
-func getDreamIdField(name string) (id string) {
-	var ok bool
-	if id, ok = dreamIdFieldMap[name]; ok {
-		return
-	}
-
-	// Validation of Goal must make before calling this method
-	dType := reflect.TypeOf(GetGoal(name)())
-	for {
-		// TODO: refactor
-		if dType.Kind() == reflect.Ptr {
-			dType = dType.Elem()
-		} else {
-			break
-		}
-	}
-
-	for i := 0; i < dType.NumField(); i++ {
-		field := dType.Field(i)
-		gogetterTag := field.Tag.Get("gogetter")
-		if gogetterTag == "id" {
-			id = field.Name
-			break
-		}
-	}
-
-	if id == "" {
-		if _, ok := dType.FieldByName(defaultTableId); ok {
-			id = defaultTableId
-		}
-	}
-
-	dreamIdFieldMap[name] = id
-	return
+	defaultRegistry.SetDefaultTableId(name)
 }
 
 // See (gg *GoGetter) Apocalypse.
@@ -457,32 +686,69 @@ func Apocalypse(names ...string) (err error) {
 // Apocalypse is designed as a handy method to replace AllInVain in cases like
 // simply wipe out all data created by Grow/Realize.
 //
+// Goals are destroyed in an order that respects declared dependencies (see
+// DependsOn), and then in the reverse order of their creation, see SetCleanupOrder.
+// Failures are returned in an *ApocalypseError, by default, Apocalypse continues
+// with the other goals after a failure, see SetStopOnCleanupError.
+//
 // Usage:
 //
 // 	gogetter.Apocalypse("Users") // Will remove all "Users" data
 // 	gogetter.Apocalypse("Users", "Another Goals") // Will remove all "Users" and "Another Goals" data
 // 	gogetter.Apocalypse() // Will destroy all data
 //
+// With CleanupByRollback, Apocalypse without names rolls back the transaction
+// instead, and with CleanupByTruncation, tables of the goals are truncated, see
+// SetCleanupStrategy.
 func (gg *GoGetter) Apocalypse(names ...string) (err error) {
+	if len(names) == 0 && gg.getOptions().cleanupStrategy == CleanupByRollback {
+		if er := gg.rollback(); er != nil {
+			err = &ApocalypseError{Errors: []*CleanupError{{Err: er}}}
+		}
+		return
+	}
+
 	if len(names) == 0 {
+		gg.mutex.Lock()
 		for k, _ := range gg.dreams {
 			names = append(names, k)
 		}
-	}
+		gg.mutex.Unlock()
+	}
+
+	if gg.getOptions().cleanupStrategy == CleanupByTruncation {
+		return gg.truncate(names)
+	}
+
+	stopOnCleanupError := gg.getOptions().stopOnCleanupError
+	apocalypseErr := &ApocalypseError{}
+	for _, layer := range gg.cleanupLayers(names) {
+		errchan := make(chan *CleanupError)
+		for i, _ := range layer {
+			name := layer[i]
+			go func() {
+				er := gg.AllInVain(name)
+				if er == nil {
+					errchan <- nil
+					return
+				}
+				table, _ := gg.registry.GetTableName(name)
+				errchan <- &CleanupError{Goal: name, Table: table, Err: er}
+			}()
+		}
+		for i := 0; i < len(layer); i++ {
+			if er := <-errchan; er != nil {
+				apocalypseErr.Errors = append(apocalypseErr.Errors, er)
+			}
+		}
 
-	errchan := make(chan error)
-	for i, _ := range names {
-		name := names[i]
-		go func() {
-			errchan <- gg.AllInVain(name)
-		}()
-	}
-	for i := 0; i < len(names); i++ {
-		er := <-errchan
-		if er == nil {
-			continue
+		if len(apocalypseErr.Errors) > 0 && stopOnCleanupError {
+			break
 		}
-		err = errors.New(err.Error() + er.Error())
+	}
+
+	if len(apocalypseErr.Errors) > 0 {
+		err = apocalypseErr
 	}
 
 	return
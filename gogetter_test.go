@@ -1,9 +1,12 @@
 package gogetter
 
 import (
-	"github.com/bom-d-van/gogetter/mgodriver"
+	"fmt"
+	"github.com/bom-d-van/gogetter/memdriver"
 	"labix.org/v2/mgo/bson"
 	. "launchpad.net/gocheck"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -23,6 +26,53 @@ type User struct {
 	ThereGreatIdeas [3]string
 }
 
+// fakeDb records what gogetter does, and assigns ids to pointer records
+// without an id, like what some databases do.
+type fakeDb struct {
+	created  map[string][]interface{}
+	removed  map[string][]interface{}
+	removals []string
+	failures map[string]error
+	lastId   int
+	mutex    sync.Mutex
+}
+
+func newFakeDb() *fakeDb {
+	return &fakeDb{
+		created:  map[string][]interface{}{},
+		removed:  map[string][]interface{}{},
+		failures: map[string]error{},
+	}
+}
+
+func (db *fakeDb) Create(table string, records ...interface{}) (err error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	for _, record := range records {
+		v := reflect.ValueOf(record)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if id := v.FieldByName("Serial"); id.IsValid() && id.CanSet() && id.Int() == 0 {
+			db.lastId++
+			id.SetInt(int64(db.lastId))
+		}
+	}
+	db.created[table] = append(db.created[table], records...)
+	return
+}
+
+func (db *fakeDb) Remove(table string, idField string, ids ...interface{}) (err error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	if err = db.failures[table]; err != nil {
+		return
+	}
+	db.removed[table] = append(db.removed[table], ids...)
+	db.removals = append(db.removals, table)
+	return
+}
+
 // func (u User) Identity() interface{} {
 // 	return u.Id
 // }
@@ -178,10 +228,25 @@ func (s *GoGetterSuite) TestCollectDreamsCorrectly(c *C) {
 	// gg.Realize(name, ...)
 }
 
+func (s *GoGetterSuite) TestPreserveLessonOrder(c *C) {
+	gg := NewGoGetter(nil)
+	lessons := []Lesson{}
+	for i := 0; i < 32; i++ {
+		lessons = append(lessons, Lesson{"Name": fmt.Sprint(i)})
+	}
+	usersI, err := gg.Grow("User", lessons...)
+	c.Check(err, Equals, nil)
+	users := usersI.([]User)
+	for i, user := range users {
+		c.Check(user.Name, Equals, fmt.Sprint(i))
+		c.Check(gg.dreams["User"][i].(User).Name, Equals, fmt.Sprint(i))
+	}
+}
+
 func (s *GoGetterSuite) TestRealize(c *C) {
-	db := getTestDb()
-	SetDefaultGetterDb(mgodriver.NewMongoDb(db))
-	usersI, err := Realize("User", Lesson{
+	db := memdriver.NewMemDb()
+	gg := NewGoGetter(db)
+	usersI, err := gg.Realize("User", Lesson{
 		"Id":   bson.NewObjectId(),
 		"Name": "New Name",
 		"Dream": &DreamS{
@@ -195,14 +260,11 @@ func (s *GoGetterSuite) TestRealize(c *C) {
 	users, ok := usersI.([]User)
 	c.Check(ok, Equals, true)
 
-	usersInDb := []User{}
-	err = db.C("users").FindId(bson.M{"$in": []bson.ObjectId{users[0].Id, users[1].Id}}).All(&usersInDb)
-	c.Check(err, Equals, nil)
-	c.Check(usersInDb[0].Id, Equals, users[0].Id)
-	c.Check(usersInDb[1].Id, Equals, users[1].Id)
-
-	_, err = db.C("users").RemoveAll(bson.M{"_id": bson.M{"$in": []bson.ObjectId{users[0].Id, users[1].Id}}})
-	c.Check(err, Equals, nil)
+	usersInDb := db.All("users")
+	c.Assert(usersInDb, HasLen, 2)
+	c.Check(usersInDb[0].(User).Id, Equals, users[0].Id)
+	c.Check(usersInDb[1].(User).Id, Equals, users[1].Id)
+	c.Check(usersInDb[0].(User).Dream.Title, Equals, "Conquer the world")
 }
 
 func (s *GoGetterSuite) TestGetTableName(c *C) {
@@ -237,26 +299,21 @@ func (s *GoGetterSuite) TestRetrieveDreamId(c *C) {
 
 func (s *GoGetterSuite) TestGetDreamIdField(c *C) {
 	cidCalledCount := 0
+	type customId struct {
+		CustomId string `gogetter:"id"`
+	}
 	SetGoal("CustomId", func() Dream {
 		cidCalledCount += 1
-		return struct {
-			CustomId string `gogetter:"id"`
-		}{}
+		return customId{}
 	})
 
-	c.Check(getDreamIdField("CustomId"), Equals, "CustomId")
+	c.Check(defaultRegistry.getDreamIdField("CustomId", reflect.TypeOf(&customId{})), Equals, "CustomId")
 
-	// Should cached DreamId
-	getDreamIdField("CustomId")
-	getDreamIdField("CustomId")
-	c.Check(cidCalledCount, Equals, 1)
-
-	SetGoal("WithOutId", func() Dream {
-		return struct {
-		}{}
-	})
+	// Should cached DreamId, without calling the Goal
+	c.Check(defaultRegistry.getDreamIdField("CustomId", nil), Equals, "CustomId")
+	c.Check(cidCalledCount, Equals, 0)
 
-	c.Check(getDreamIdField("WithOutId"), Equals, "")
+	c.Check(defaultRegistry.getDreamIdField("WithOutId", reflect.TypeOf(struct{}{})), Equals, "")
 }
 
 func (s *GoGetterSuite) TestGetTableNameOfAscendGoals(c *C) {
@@ -282,13 +339,32 @@ func (s *GoGetterSuite) TestNestedAscendGoals(c *C) {
 	c.Check(user.Dream.Title, Equals, "Super Super Dream")
 }
 
-// func (s *GoGetterSuite) TestGetWithInspiration(c *C) {
-// 	user, err := Grow("*Pointer User", Lesson{
-// 		"Name": func() Dream {
-// 			return "Name Filled by Inspiration"
-// 		},
-// 	})
-// 	c.Check(err, Equals, nil)
-// 	c.Check((**user.(**User)).Name, Equals, "Name Filled by Inspiration")
-// 	c.Check((**user.(**User)).Dream.Title, Equals, "Conquer the world")
-// }
+func (s *GoGetterSuite) TestGetWithInspiration(c *C) {
+	user, err := Grow("*Pointer User", Lesson{
+		"Name": Inspiration(func(dream Dream, index int) Dream {
+			return "Name Filled by Inspiration"
+		}),
+		"Dream": &DreamS{
+			Title: "Conquer the world",
+		},
+	})
+	c.Check(err, Equals, nil)
+	c.Check((**user.(**User)).Name, Equals, "Name Filled by Inspiration")
+	c.Check((**user.(**User)).Dream.Title, Equals, "Conquer the world")
+}
+
+func (s *GoGetterSuite) TestInspirationWithDreamAndIndex(c *C) {
+	inspiration := func(dream Dream, index int) Dream {
+		return []string{dream.(*User).Name, fmt.Sprint(index)}
+	}
+	usersI, err := Grow("Super User", Lesson{
+		"VisitedPlaces": inspiration,
+	}, Lesson{
+		"Name":          "No.2",
+		"VisitedPlaces": inspiration,
+	})
+	c.Check(err, Equals, nil)
+	users := usersI.([]User)
+	c.Check(users[0].VisitedPlaces, DeepEquals, []string{"Super User", "0"})
+	c.Check(users[1].VisitedPlaces, DeepEquals, []string{"No.2", "1"})
+}
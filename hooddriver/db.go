@@ -2,36 +2,346 @@ package hooddriver
 
 import (
 	"database/sql"
+	"fmt"
+	"github.com/bom-d-van/gogetter"
 	"github.com/eaigner/hood"
 	_ "github.com/go-sql-driver/mysql"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
+var (
+	idType   = reflect.TypeOf(hood.Id(0))
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// Hood is a gogetter Database for MySQL on top of hood. Records are created in
+// a transaction, which is rolled back if anything fails.
+//
+// Records are inserted in the table passed by gogetter, which is created if it
+// doesn't exist, with the columns hood would use for the record type: snake
+// case of field names, MySQL types of hood, and the first hood.Id field as the
+// auto-increment primary key. Fields tagged with `sql:"-"` are left out, and
+// `sql:"size(255)"`, `sql:"notnull"` and `sql:"default(0)"` are respected.
+//
+// Zero hood.Id fields are left out for MySQL to generate them, the generated
+// ids are written back to records passed by pointers.
 type Hood struct {
 	hood *hood.Hood
+
+	// tables are the ones known to exist
+	tables map[string]bool
+	mutex  sync.Mutex
 }
 
+var _ gogetter.Storer = &Hood{}
+
 func NewHood(hood *hood.Hood) *Hood {
-	return &Hood{hood: hood}
+	return &Hood{hood: hood, tables: map[string]bool{}}
 }
 
 func (m *Hood) Create(table string, records ...interface{}) (err error) {
+	if len(records) == 0 {
+		return
+	}
+
+	values := []reflect.Value{}
+	for _, record := range records {
+		v := reflect.ValueOf(record)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct || (len(values) > 0 && v.Type() != values[0].Type()) {
+			return fmt.Errorf("hooddriver: can't create %T in %s", record, table)
+		}
+		values = append(values, v)
+	}
+
+	// CREATE TABLE causes an implicit commit, so it's out of the transaction.
+	if err = m.createTable(table, values[0].Type()); err != nil {
+		return
+	}
+
 	hd := m.hood.Begin()
-	err = hd.CreateTableIfNotExists(&User{})
-	if err != nil {
+	defer func() {
+		if err != nil {
+			hd.Rollback()
+		} else {
+			err = hd.Commit()
+		}
+	}()
+
+	return insert(hd, table, values)
+}
+
+// Store implements gogetter.Storer, it's the same as Create, which writes ids
+// back to the pointers gogetter passes.
+func (m *Hood) Store(table string, records ...interface{}) error {
+	return m.Create(table, records...)
+}
+
+// createTable creates table for records of type t, if it's not known to exist.
+func (m *Hood) createTable(table string, t reflect.Type) (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.tables[table] {
 		return
 	}
-	hd.Commit()
 
-	hd = m.hood.Begin()
-	_, err = hd.SaveAll(&records)
-	hd.Commit()
+	columns := []string{}
+	pk := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("sql") == "-" {
+			continue
+		}
+
+		tags := parseTags(field.Tag.Get("sql"))
+		sqlType, ok := sqlTypeOf(field.Type, tags["size"])
+		if !ok {
+			return fmt.Errorf("hooddriver: can't create column of %s %s in %s", field.Name, field.Type, table)
+		}
+		column := quote(snakeCase(field.Name)) + " " + sqlType
+		if field.Type == idType && !pk {
+			column += " NOT NULL AUTO_INCREMENT PRIMARY KEY"
+			pk = true
+		} else {
+			if _, ok := tags["notnull"]; ok {
+				column += " NOT NULL"
+			}
+			if value, ok := tags["default"]; ok {
+				column += " DEFAULT " + value
+			}
+		}
+		columns = append(columns, column)
+	}
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quote(table), strings.Join(columns, ", "))
+	if _, err = m.hood.Exec(query); err != nil {
+		return
+	}
+	m.tables[table] = true
+
+	return
+}
+
+// sqlTypeOf returns the MySQL type of columns of Go type t, the same as hood.
+func sqlTypeOf(t reflect.Type, size string) (string, bool) {
+	n, _ := strconv.Atoi(size)
+	switch {
+	case t == idType:
+		return "bigint", true
+	case t == timeType:
+		return "timestamp", true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		if n > 0 && n < 65532 {
+			return fmt.Sprintf("varbinary(%d)", n), true
+		}
+		return "longblob", true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "int", true
+	case reflect.Int64, reflect.Uint64:
+		return "bigint", true
+	case reflect.Float32, reflect.Float64:
+		return "double", true
+	case reflect.String:
+		if n > 0 && n < 65532 {
+			return fmt.Sprintf("varchar(%d)", n), true
+		}
+		return "longtext", true
+	}
+
+	return "", false
+}
+
+// parseTags parses sql tags of hood, e.g. "size(255),notnull" into
+// {"size": "255", "notnull": ""}.
+func parseTags(tag string) map[string]string {
+	tags := map[string]string{}
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if i := strings.Index(part, "("); i > 0 && strings.HasSuffix(part, ")") {
+			tags[part[:i]] = part[i+1 : len(part)-1]
+		} else {
+			tags[part] = ""
+		}
+	}
+
+	return tags
+}
+
+// insert inserts values into table. Zero hood.Id fields are left out for MySQL
+// to generate them, so values are inserted in one statement per set of zero
+// hood.Id fields, in the order of their first values. Values with zero ids
+// which could be set are inserted one by one, with the generated ids written
+// back to their first hood.Id fields.
+func insert(hd *hood.Hood, table string, values []reflect.Value) (err error) {
+	groups := [][]reflect.Value{}
+	indexes := map[string]int{}
+	for _, v := range values {
+		key := zeroIds(v)
+		if key != "" && v.CanSet() {
+			groups = append(groups, []reflect.Value{v})
+			continue
+		}
+		i, ok := indexes[key]
+		if !ok {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], v)
+	}
+
+	for _, group := range groups {
+		var result sql.Result
+		if result, err = insertRows(hd, table, group); err != nil {
+			return
+		}
+		if v := group[0]; len(group) == 1 && v.CanSet() {
+			if err = writeId(v, result); err != nil {
+				return
+			}
+		}
+	}
 
 	return
 }
 
+// writeId sets the first hood.Id field of v to the id generated by MySQL, if
+// it's zero.
+func writeId(v reflect.Value, result sql.Result) (err error) {
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Type != idType {
+			continue
+		}
+		if v.Field(i).Int() == 0 {
+			var id int64
+			if id, err = result.LastInsertId(); err != nil {
+				return
+			}
+			v.Field(i).SetInt(id)
+		}
+		return
+	}
+
+	return
+}
+
+// zeroIds returns the indexes of the zero hood.Id fields of v, as a key.
+func zeroIds(v reflect.Value) string {
+	key := []byte{}
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Type == idType && v.Field(i).Int() == 0 {
+			key = append(key, byte(i))
+		}
+	}
+
+	return string(key)
+}
+
+// insertRows inserts values with the same zero hood.Id fields in one statement.
+func insertRows(hd *hood.Hood, table string, values []reflect.Value) (sql.Result, error) {
+	fields := []int{}
+	columns := []string{}
+	t := values[0].Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("sql") == "-" {
+			continue
+		}
+		if field.Type == idType && values[0].Field(i).Int() == 0 {
+			continue
+		}
+		fields = append(fields, i)
+		columns = append(columns, quote(snakeCase(field.Name)))
+	}
+
+	rows := []string{}
+	args := []interface{}{}
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(fields)), ", ") + ")"
+	for _, v := range values {
+		for _, i := range fields {
+			args = append(args, v.Field(i).Interface())
+		}
+		rows = append(rows, placeholders)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", quote(table), strings.Join(columns, ", "), strings.Join(rows, ", "))
+	return hd.Exec(query, args...)
+}
+
+// Remove deletes records by ids, idField is mapped to its column the same way
+// as hood does, e.g. "AuthorId" to "author_id".
 func (m *Hood) Remove(table string, idField string, ids ...interface{}) (err error) {
+	if len(ids) == 0 {
+		return
+	}
+
 	hd := m.hood.Begin()
-	err = hd.Where(idField, "IN", ids).DeleteFrom(table)
-	hd.Commit()
-	return
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", quote(table), quote(snakeCase(idField)), placeholders)
+	if _, err = hd.Exec(query, ids...); err != nil {
+		hd.Rollback()
+		return
+	}
+
+	return hd.Commit()
+}
+
+// Truncate truncates the tables with foreign key checks disabled, so that they
+// could be truncated in any order.
+func (m *Hood) Truncate(tables ...string) (err error) {
+	hd := m.hood.Begin()
+	if _, err = hd.Exec("SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		hd.Rollback()
+		return
+	}
+	for _, table := range tables {
+		if _, err = hd.Exec("TRUNCATE TABLE " + quote(table)); err != nil {
+			break
+		}
+	}
+	if _, er := hd.Exec("SET FOREIGN_KEY_CHECKS = 1"); err == nil {
+		err = er
+	}
+	if err != nil {
+		hd.Rollback()
+		return
+	}
+
+	return hd.Commit()
+}
+
+func quote(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// snakeCase converts names the same way as hood, e.g. "AuthorId" into "author_id".
+func snakeCase(name string) string {
+	out := []rune{}
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+
+	return string(out)
 }
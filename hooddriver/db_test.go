@@ -0,0 +1,162 @@
+package hooddriver
+
+import (
+	"github.com/bom-d-van/gogetter"
+	"github.com/bom-d-van/gogetter/drivertest"
+	"github.com/eaigner/hood"
+	. "launchpad.net/gocheck"
+	"reflect"
+	"testing"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type HoodSuite struct{ *Hood }
+
+var _ = Suite(&HoodSuite{})
+
+var _ = Suite(&drivertest.Suite{New: func(c *C) *drivertest.Fixture {
+	hd, err := hood.Open("mysql", "root@/gogetter")
+	if err == nil {
+		err = hd.Db.Ping()
+	}
+	if err != nil {
+		c.Skip("MySQL is not available: " + err.Error())
+	}
+	_, err = hd.Exec("CREATE TABLE IF NOT EXISTS drivertest_records (id BIGINT PRIMARY KEY, code VARCHAR(255), name VARCHAR(255))")
+	c.Assert(err, Equals, nil)
+	_, err = hd.Exec("DELETE FROM drivertest_records")
+	c.Assert(err, Equals, nil)
+	return &drivertest.Fixture{
+		Db: NewHood(hd),
+		Count: func(table string) (n int, err error) {
+			err = hd.QueryRow("SELECT COUNT(*) FROM " + quote(table)).Scan(&n)
+			return
+		},
+		Close: func() { hd.Db.Close() },
+	}
+}})
+
+type HoodUser struct {
+	Id   hood.Id
+	Name string `sql:"size(255)"`
+}
+
+type Member struct {
+	Id    hood.Id
+	Name  string
+	Cache string `sql:"-"`
+}
+
+func (s *HoodSuite) SetUpSuite(c *C) {
+	hd, err := hood.Open("mysql", "root@/gogetter")
+	if err == nil {
+		err = hd.Db.Ping()
+	}
+	if err != nil {
+		c.Skip("MySQL is not available: " + err.Error())
+	}
+	s.Hood = NewHood(hd)
+	_, err = hd.Exec("CREATE TABLE IF NOT EXISTS members (id BIGINT AUTO_INCREMENT PRIMARY KEY, name VARCHAR(255))")
+	c.Assert(err, Equals, nil)
+}
+
+func (s *HoodSuite) SetUpTest(c *C) {
+	s.Hood = NewHood(s.hood)
+	_, err := s.hood.Exec("DROP TABLE IF EXISTS hood_users")
+	c.Assert(err, Equals, nil)
+	_, err = s.hood.Exec("DELETE FROM members")
+	c.Assert(err, Equals, nil)
+}
+
+func (s *HoodSuite) count(c *C, table string) (n int) {
+	err := s.hood.QueryRow("SELECT COUNT(*) FROM " + quote(table)).Scan(&n)
+	c.Assert(err, Equals, nil)
+	return
+}
+
+func (s *HoodSuite) TestCreateWithSchema(c *C) {
+	user := &HoodUser{Name: "Van"}
+	err := s.Create("hood_users", user, HoodUser{Name: "Gogh"}, &HoodUser{Name: "Vincent"})
+	c.Check(err, Equals, nil)
+	c.Check(user.Id, Not(Equals), hood.Id(0))
+	c.Check(s.count(c, "hood_users"), Equals, 3)
+
+	c.Check(s.Remove("hood_users", "Id", user.Id), Equals, nil)
+	c.Check(s.count(c, "hood_users"), Equals, 2)
+
+	var sqlType string
+	err = s.hood.QueryRow("SELECT COLUMN_TYPE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'hood_users' AND COLUMN_NAME = 'name'").Scan(&sqlType)
+	c.Check(err, Equals, nil)
+	c.Check(sqlType, Equals, "varchar(255)")
+}
+
+func (s *HoodSuite) TestSqlTypes(c *C) {
+	for t, sqlType := range map[reflect.Type]string{
+		idType:                   "bigint",
+		timeType:                 "timestamp",
+		reflect.TypeOf(true):     "boolean",
+		reflect.TypeOf(int8(0)):  "int",
+		reflect.TypeOf(int64(0)): "bigint",
+		reflect.TypeOf(0.1):      "double",
+		reflect.TypeOf(""):       "longtext",
+		reflect.TypeOf([]byte{}): "longblob",
+	} {
+		typ, ok := sqlTypeOf(t, "")
+		c.Check(ok, Equals, true)
+		c.Check(typ, Equals, sqlType)
+	}
+	typ, _ := sqlTypeOf(reflect.TypeOf(""), "255")
+	c.Check(typ, Equals, "varchar(255)")
+	_, ok := sqlTypeOf(reflect.TypeOf(map[string]string{}), "")
+	c.Check(ok, Equals, false)
+	c.Check(parseTags("size(255), notnull,default('x')"), DeepEquals, map[string]string{"size": "255", "notnull": "", "default": "'x'"})
+}
+
+func (s *HoodSuite) TestCreateInTable(c *C) {
+	err := s.Create("members", Member{Name: "Van", Cache: "x"}, &Member{Id: 10, Name: "Gogh"})
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, "members"), Equals, 2)
+
+	c.Check(s.Remove("members", "Id", 10), Equals, nil)
+	c.Check(s.count(c, "members"), Equals, 1)
+	c.Check(s.Truncate("members"), Equals, nil)
+	c.Check(s.count(c, "members"), Equals, 0)
+}
+
+func (s *HoodSuite) TestCreateMixedIds(c *C) {
+	err := s.Create("members", Member{Name: "Van"}, &Member{Id: 10, Name: "Gogh"}, Member{Name: "Vincent"})
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, "members"), Equals, 3)
+	c.Check(zeroIds(reflect.ValueOf(Member{})), Not(Equals), zeroIds(reflect.ValueOf(Member{Id: 10})))
+
+	c.Check(s.Remove("members", "Id", 10), Equals, nil)
+	c.Check(s.count(c, "members"), Equals, 2)
+}
+
+func (s *HoodSuite) TestWithGoGetter(c *C) {
+	reg := gogetter.NewRegistry(nil)
+	reg.SetGoal("Hood User", func() gogetter.Dream { return HoodUser{Name: "Van"} })
+	gg := gogetter.NewGoGetter(s.Hood, reg)
+
+	usersI, err := gg.Realize("*Hood User", nil, nil)
+	c.Check(err, Equals, nil)
+	c.Check(usersI.([]*HoodUser)[1].Id, Not(Equals), hood.Id(0))
+	c.Check(s.count(c, "hood_users"), Equals, 2)
+	c.Check(gg.Apocalypse(), Equals, nil)
+	c.Check(s.count(c, "hood_users"), Equals, 0)
+}
+
+func (s *HoodSuite) TestRollbackOnError(c *C) {
+	err := s.Create("members", Member{Name: "Van"}, HoodUser{})
+	c.Check(err, ErrorMatches, "hooddriver: can't create hooddriver.HoodUser in members")
+	err = s.Create("members", Member{Name: "Van"}, Member{Id: 10}, Member{Id: 10})
+	c.Check(err, Not(Equals), nil)
+	c.Check(s.count(c, "members"), Equals, 0)
+}
+
+func (s *HoodSuite) TestSnakeCase(c *C) {
+	c.Check(snakeCase("HoodUser"), Equals, "hood_user")
+	c.Check(snakeCase("AuthorId"), Equals, "author_id")
+}
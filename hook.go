@@ -0,0 +1,102 @@
+package gogetter
+
+import (
+	"reflect"
+)
+
+// Hook is called with a pointer to the struct of a dream, e.g. *User for all of
+// "User", "*User" and "*Pointer User", so it could modify the dream. Returning
+// an error stops Grow/Realize.
+type Hook func(dream Dream) error
+
+type hookMoment int
+
+const (
+	afterBuild hookMoment = iota
+	beforeCreate
+	afterCreate
+)
+
+// See (r *Registry) AfterBuild.
+func AfterBuild(name string, hook Hook) {
+	defaultRegistry.AfterBuild(name, hook)
+}
+
+// See (r *Registry) BeforeCreate.
+func BeforeCreate(name string, hook Hook) {
+	defaultRegistry.BeforeCreate(name, hook)
+}
+
+// See (r *Registry) AfterCreate.
+func AfterCreate(name string, hook Hook) {
+	defaultRegistry.AfterCreate(name, hook)
+}
+
+// AfterBuild registers a hook called after the dreams of the goal are built by
+// Grow/Realize, with all the Lessons applied.
+func (r *Registry) AfterBuild(name string, hook Hook) {
+	r.addHook(name, afterBuild, hook)
+}
+
+// BeforeCreate registers a hook called by Realize before the dreams of the goal
+// are created in database.
+func (r *Registry) BeforeCreate(name string, hook Hook) {
+	r.addHook(name, beforeCreate, hook)
+}
+
+// AfterCreate registers a hook called by Realize after the dreams of the goal are
+// created in database, values written back by the database, if any, are
+// available in the dream, e.g. auto-increment ids. Note that Database.Create
+// receives dreams of value goals as copies, so only values written back to
+// pointer goals, e.g. "*Article", or by a Storer are available.
+func (r *Registry) AfterCreate(name string, hook Hook) {
+	r.addHook(name, afterCreate, hook)
+}
+
+func (r *Registry) addHook(name string, moment hookMoment, hook Hook) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.hooks[name] == nil {
+		r.hooks[name] = map[hookMoment][]Hook{}
+	}
+	r.hooks[name][moment] = append(r.hooks[name][moment], hook)
+}
+
+// getHooks returns hooks of the goal and its parents, the ones of parents come
+// first, and the ones of parent registries come first for the same goal.
+func (r *Registry) getHooks(name string, moment hookMoment) (hooks []Hook) {
+	for _, goal := range r.getGoalChain(name) {
+		own := []Hook{}
+		for reg := r; reg != nil; reg = reg.parent {
+			reg.mutex.RLock()
+			own = append(append([]Hook{}, reg.hooks[goal][moment]...), own...)
+			reg.mutex.RUnlock()
+		}
+		hooks = append(own, hooks...)
+	}
+
+	return
+}
+
+// runHooks calls the hooks with every dream in goals, in order.
+func (r *Registry) runHooks(name string, moment hookMoment, goals reflect.Value) (err error) {
+	hooks := r.getHooks(name, moment)
+	if len(hooks) == 0 {
+		return
+	}
+
+	for i := 0; i < goals.Len(); i++ {
+		dream := goals.Index(i)
+		for dream.Kind() == reflect.Ptr {
+			dream = dream.Elem()
+		}
+		for _, hook := range hooks {
+			if err = hook(dream.Addr().Interface()); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,94 @@
+package gogetter
+
+import (
+	"errors"
+	. "launchpad.net/gocheck"
+)
+
+type Article struct {
+	Serial   int
+	Title    string
+	Slug     string
+	Created  bool
+	Moments  []string
+	Reviewed bool
+}
+
+func init() {
+	SetGoal("Article", func() Dream { return Article{Title: "Title"} })
+	AfterBuild("Article", func(dream Dream) error {
+		article := dream.(*Article)
+		article.Slug = "slug-of-" + article.Title
+		article.Moments = append(article.Moments, "after build")
+		return nil
+	})
+	BeforeCreate("Article", func(dream Dream) error {
+		dream.(*Article).Moments = append(dream.(*Article).Moments, "before create")
+		return nil
+	})
+	AfterCreate("Article", func(dream Dream) error {
+		article := dream.(*Article)
+		article.Created = article.Serial > 0
+		article.Moments = append(article.Moments, "after create")
+		return nil
+	})
+
+	AscendGoal("Reviewed Article", "Article", func() Lesson { return Lesson{} })
+	AfterBuild("Reviewed Article", func(dream Dream) error {
+		dream.(*Article).Reviewed = true
+		dream.(*Article).Moments = append(dream.(*Article).Moments, "reviewed")
+		return nil
+	})
+}
+
+func (s *GoGetterSuite) TestHooks(c *C) {
+	gg := NewGoGetter(newFakeDb())
+	articleI, err := gg.Grow("Article", Lesson{"Title": "Hooks"})
+	c.Check(err, Equals, nil)
+	article := articleI.(Article)
+	c.Check(article.Slug, Equals, "slug-of-Hooks")
+	c.Check(article.Moments, DeepEquals, []string{"after build"})
+
+	articlesI, err := gg.Realize("*Article", nil, nil)
+	c.Check(err, Equals, nil)
+	articles := articlesI.([]*Article)
+	c.Check(articles[0].Moments, DeepEquals, []string{"after build", "before create", "after create"})
+	c.Check(articles[1].Serial, Equals, 2)
+	c.Check(articles[1].Created, Equals, true)
+	c.Check(gg.dreams["Article"][2].(*Article).Created, Equals, true)
+}
+
+// Values written back by Create to copies of value dreams are lost, unlike
+// the ones written back by a Storer, see TestStore.
+func (s *GoGetterSuite) TestHooksOfValueGoals(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	articleI, err := gg.Realize("Article")
+	c.Check(err, Equals, nil)
+	c.Check(articleI.(Article).Serial, Equals, 0)
+	c.Check(articleI.(Article).Created, Equals, false)
+	c.Check(articleI.(Article).Moments, DeepEquals, []string{"after build", "before create", "after create"})
+	c.Check(db.created["articles"], HasLen, 1)
+}
+
+func (s *GoGetterSuite) TestHooksOfAscendGoals(c *C) {
+	articleI, err := Grow("Reviewed Article")
+	c.Check(err, Equals, nil)
+	article := articleI.(Article)
+	c.Check(article.Reviewed, Equals, true)
+	c.Check(article.Moments, DeepEquals, []string{"after build", "reviewed"})
+}
+
+func (s *GoGetterSuite) TestHookErrors(c *C) {
+	hookErr := errors.New("hook error")
+	SetGoal("Failed Article", func() Dream { return Article{} })
+	BeforeCreate("Failed Article", func(dream Dream) error { return hookErr })
+
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	_, err := gg.Grow("Failed Article")
+	c.Check(err, Equals, nil)
+	_, err = gg.Realize("Failed Article")
+	c.Check(err, Equals, hookErr)
+	c.Check(db.created["failed_articles"], HasLen, 0)
+}
@@ -0,0 +1,151 @@
+package gogetter
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// IdGenerator generates ids for dreams having zero id fields.
+type IdGenerator func() Dream
+
+// See (r *Registry) SetIdGenerator.
+func SetIdGenerator(name string, generator IdGenerator) {
+	defaultRegistry.SetIdGenerator(name, generator)
+}
+
+// SetIdGenerator makes gogetter fill the id field (see SetDefaultTableId) of
+// dreams of the goal with generator before creating them, if it's zero, so
+// that they could be destroyed by their ids. Goals ascended from the goal share
+// its generator, a nil generator disables the generation for the goal.
+//
+// Usage:
+//
+// 	gogetter.SetIdGenerator("User", func() gogetter.Dream { return bson.NewObjectId() })
+// 	gogetter.SetIdGenerator("Post", gogetter.IntSequence(1000))
+func (r *Registry) SetIdGenerator(name string, generator IdGenerator) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.idGenerators[name] = generator
+}
+
+// See (r *Registry) RegisterIdGenerator.
+func RegisterIdGenerator(t reflect.Type, generator IdGenerator) {
+	defaultRegistry.RegisterIdGenerator(t, generator)
+}
+
+// RegisterIdGenerator makes generator the one of id fields of type t, for goals
+// without their own generators set by SetIdGenerator.
+//
+// 	gogetter.RegisterIdGenerator(reflect.TypeOf(bson.ObjectId("")), func() gogetter.Dream {
+// 		return bson.NewObjectId()
+// 	})
+func (r *Registry) RegisterIdGenerator(t reflect.Type, generator IdGenerator) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.typeIdGenerators[t] = generator
+}
+
+func (r *Registry) getIdGenerator(name string, t reflect.Type) IdGenerator {
+	for _, goal := range r.getGoalChain(name) {
+		for reg := r; reg != nil; reg = reg.parent {
+			reg.mutex.RLock()
+			generator, ok := reg.idGenerators[goal]
+			reg.mutex.RUnlock()
+			if ok {
+				return generator
+			}
+		}
+	}
+
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		generator, ok := reg.typeIdGenerators[t]
+		reg.mutex.RUnlock()
+		if ok {
+			return generator
+		}
+	}
+
+	return nil
+}
+
+// generateIds fills the zero id fields of goals with the generator of the goal.
+func (r *Registry) generateIds(name string, goals reflect.Value) (err error) {
+	idField := r.getDreamIdField(name, goals.Type().Elem())
+	if idField == "" {
+		return
+	}
+
+	for i := 0; i < goals.Len(); i++ {
+		dream := goals.Index(i)
+		for dream.Kind() == reflect.Ptr {
+			dream = dream.Elem()
+		}
+		field := dream.FieldByName(idField)
+		if !field.CanSet() || !reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+			continue
+		}
+
+		generator := r.getIdGenerator(name, field.Type())
+		if generator == nil {
+			return
+		}
+		id := reflect.ValueOf(generator())
+		switch {
+		case !id.IsValid():
+			return fmt.Errorf("gogetter: id generator of %q returned nil", name)
+		case id.Type().AssignableTo(field.Type()):
+			field.Set(id)
+		case id.Type().ConvertibleTo(field.Type()) && isSafeConversion(id, field.Type()):
+			field.Set(id.Convert(field.Type()))
+		default:
+			return fmt.Errorf("gogetter: can't set generated id %T to %s of %q", id.Interface(), idField, name)
+		}
+	}
+
+	return
+}
+
+// IntSequence returns a generator of int64 ids counting from start, which is
+// shared by all the goals and GoGetters using it. Ids are converted into the
+// integer types of id fields.
+func IntSequence(start int64) IdGenerator {
+	next := start - 1
+	return func() Dream {
+		return atomic.AddInt64(&next, 1)
+	}
+}
+
+// UUIDv4 generates random UUIDs (RFC 4122 version 4) in their string form.
+func UUIDv4() Dream {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+
+	return formatUUID(u)
+}
+
+// UUIDv7 generates UUIDs (RFC 9562 version 7) in their string form, which are
+// ordered by the milliseconds they are generated in.
+func UUIDv7() Dream {
+	var u [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	copy(u[:6], ms[2:])
+	rand.Read(u[6:])
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+
+	return formatUUID(u)
+}
+
+func formatUUID(u [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
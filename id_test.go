@@ -0,0 +1,93 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+	"reflect"
+)
+
+type Badge struct {
+	Id    int
+	Label string
+}
+
+type Token struct {
+	Key  string `gogetter:"id"`
+	Name string
+}
+
+func (s *GoGetterSuite) TestIdGenerators(c *C) {
+	reg := NewRegistry(DefaultRegistry())
+	reg.SetGoal("Badge", func() Dream { return Badge{} })
+	reg.AscendGoal("Gold Badge", "Badge", func() Lesson { return Lesson{"Label": "Gold"} })
+	reg.SetGoal("Token", func() Dream { return &Token{} })
+	reg.SetIdGenerator("Badge", IntSequence(100))
+	reg.RegisterIdGenerator(reflect.TypeOf(""), UUIDv4)
+	db := newFakeDb()
+	gg := NewGoGetter(db, reg)
+
+	badgesI, err := gg.Realize("Badge", nil, Lesson{"Id": 1}, nil)
+	c.Check(err, Equals, nil)
+	c.Check(badgesI, DeepEquals, []Badge{{Id: 100}, {Id: 1}, {Id: 101}})
+	badgeI, err := gg.Realize("Gold Badge")
+	c.Check(err, Equals, nil)
+	c.Check(badgeI, DeepEquals, Badge{Id: 102, Label: "Gold"})
+
+	// Ids are generated only for dreams created
+	badgeI, err = NewGoGetter(db, reg).Grow("Badge")
+	c.Check(err, Equals, nil)
+	c.Check(badgeI, DeepEquals, Badge{})
+
+	tokenI, err := gg.Realize("Token")
+	c.Check(err, Equals, nil)
+	c.Check(tokenI.(*Token).Key, Matches, "[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}")
+	c.Check(tokenI.(*Token).Name, Equals, "")
+
+	c.Check(gg.Apocalypse(), Equals, nil)
+	c.Check(db.removed["badges"], DeepEquals, []interface{}{102, 100, 1, 101})
+	c.Check(db.removed["tokens"], DeepEquals, []interface{}{tokenI.(*Token).Key})
+
+	reg.SetIdGenerator("Gold Badge", nil)
+	badgeI, err = gg.Realize("Gold Badge")
+	c.Check(err, Equals, nil)
+	c.Check(badgeI.(Badge).Id, Equals, 0)
+}
+
+func (s *GoGetterSuite) TestIdGeneratorErrors(c *C) {
+	reg := NewRegistry(nil)
+	reg.SetGoal("Badge", func() Dream { return Badge{} })
+	reg.SetIdGenerator("Badge", UUIDv7)
+	_, err := NewGoGetter(newFakeDb(), reg).Realize("Badge")
+	c.Check(err, ErrorMatches, `gogetter: can't set generated id string to Id of "Badge"`)
+
+	reg.SetIdGenerator("Badge", func() Dream { return nil })
+	_, err = NewGoGetter(newFakeDb(), reg).Realize("Badge")
+	c.Check(err, ErrorMatches, `gogetter: id generator of "Badge" returned nil`)
+}
+
+func (s *GoGetterSuite) TestUUIDs(c *C) {
+	c.Check(UUIDv4(), Not(Equals), UUIDv4())
+	a, b := UUIDv7().(string), UUIDv7().(string)
+	c.Check(a, Matches, "[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}")
+	c.Check(a[:8] <= b[:8], Equals, true)
+}
+
+// Goals are not called to find their id fields, for that they could have side
+// effects, e.g. advancing sequences.
+func (s *GoGetterSuite) TestIdFieldsWithoutCallingGoals(c *C) {
+	calls := 0
+	reg := NewRegistry(DefaultRegistry())
+	reg.SetGoal("Counted Badge", func() Dream {
+		calls++
+		return Badge{}
+	})
+	reg.SetIdGenerator("Counted Badge", IntSequence(1))
+	gg := NewGoGetter(newFakeDb(), reg)
+
+	badgesI, err := gg.Realize("Counted Badge", nil, nil)
+	c.Check(err, Equals, nil)
+	c.Check(calls, Equals, 2)
+	c.Check(badgesI.([]Badge)[1].Id, Equals, 2)
+	c.Check(gg.AllInVain("Counted Badge"), Equals, nil)
+	c.Check(NewGoGetter(newFakeDb(), NewRegistry(reg)).AllInVain("Counted Badge", Badge{Id: 1}), Equals, nil)
+	c.Check(calls, Equals, 2)
+}
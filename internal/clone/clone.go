@@ -0,0 +1,148 @@
+// Package clone deep copies values for gogetter and its drivers.
+package clone
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// dreamCloner deep copies values reflectively, so that dreams never share any
+// slices, maps or pointers with the values returned by Goals or with each other,
+// neither do records kept by memdriver with dreams.
+//
+// Pointers already copied are remembered, which keeps cycles and pointers
+// pointing to the same value intact. Unexported fields are copied too.
+// time.Time, channels and functions are copied as they are, so are the fields
+// with a `gogetter:"shared"` tag, which is meant for values intentionally shared.
+type dreamCloner struct {
+	pointers map[clonedPointer]reflect.Value
+}
+
+type clonedPointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// DeepCopy copies src into dst, which must be settable. If src is addressable,
+// pointers to it are replaced with pointers to dst.
+func DeepCopy(dst, src reflect.Value) {
+	cloner := &dreamCloner{pointers: map[clonedPointer]reflect.Value{}}
+	if src.CanAddr() && dst.CanAddr() {
+		cloner.pointers[clonedPointer{src.UnsafeAddr(), reflect.PtrTo(src.Type())}] = dst.Addr()
+	}
+	cloner.copy(dst, src)
+}
+
+// copy copies src into dst, which must be settable.
+func (dc *dreamCloner) copy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := clonedPointer{src.Pointer(), src.Type()}
+		if p, ok := dc.pointers[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		dc.pointers[key] = p
+		dc.copy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		v := reflect.New(src.Elem().Type()).Elem()
+		dc.copy(v, src.Elem())
+		dst.Set(v)
+	case reflect.Struct:
+		if src.Type() == timeType {
+			dst.Set(src)
+			return
+		}
+		src = addressable(src)
+		for i := 0; i < src.NumField(); i++ {
+			field := src.Type().Field(i)
+			sf, df := src.Field(i), dst.Field(i)
+			if field.PkgPath != "" {
+				sf, df = exposeField(sf), exposeField(df)
+			}
+			if HasTag(field, "shared") {
+				df.Set(sf)
+				continue
+			}
+			dc.copy(df, sf)
+		}
+	case reflect.Array:
+		src = addressable(src)
+		for i := 0; i < src.Len(); i++ {
+			dc.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		for i := 0; i < src.Len(); i++ {
+			dc.copy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMap(src.Type())
+		for _, key := range src.MapKeys() {
+			k := reflect.New(key.Type()).Elem()
+			dc.copy(k, key)
+			v := reflect.New(src.Type().Elem()).Elem()
+			dc.copy(v, src.MapIndex(key))
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+	default:
+		dst.Set(src)
+	}
+}
+
+// Copy returns a deep copy of v.
+func Copy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	dst := reflect.New(reflect.TypeOf(v)).Elem()
+	DeepCopy(dst, reflect.ValueOf(v))
+	return dst.Interface()
+}
+
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	av := reflect.New(v.Type()).Elem()
+	av.Set(v)
+	return av
+}
+
+// exposeField makes an unexported field of an addressable struct readable and
+// settable.
+func exposeField(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// HasTag checks whether the gogetter tag of the field, which could be a comma
+// separated list like `gogetter:"id,shared"`, contains the option.
+func HasTag(field reflect.StructField, option string) bool {
+	for _, opt := range strings.Split(field.Tag.Get("gogetter"), ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+package memdriver
+
+import (
+	"fmt"
+	"github.com/bom-d-van/gogetter/internal/clone"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// MemDb is a gogetter Database keeping records in memory, which is handy for
+// unit tests without any database server. Records are stored as deep copies of
+// the structs they point to, so changing dreams after creating them doesn't
+// change the records, neither does changing the records returned by MemDb.
+// Ids are matched by their Go field names with reflect.DeepEqual.
+type MemDb struct {
+	mutex  sync.RWMutex
+	tables map[string][]interface{}
+}
+
+func NewMemDb() *MemDb {
+	return &MemDb{tables: map[string][]interface{}{}}
+}
+
+func (m *MemDb) Create(table string, records ...interface{}) (err error) {
+	copies := []interface{}{}
+	for _, record := range records {
+		v := reflect.ValueOf(record)
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("memdriver: can't create %T in %s", record, table)
+		}
+		copies = append(copies, clone.Copy(v.Interface()))
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.tables[table] = append(m.tables[table], copies...)
+	return
+}
+
+func (m *MemDb) Remove(table string, idField string, ids ...interface{}) (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	survived := []interface{}{}
+	for _, record := range m.tables[table] {
+		if !matches(record, idField, ids) {
+			survived = append(survived, record)
+		}
+	}
+	m.tables[table] = survived
+
+	return
+}
+
+func (m *MemDb) Truncate(tables ...string) (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, table := range tables {
+		delete(m.tables, table)
+	}
+
+	return
+}
+
+// Find returns copies of the records in table whose field equals value.
+//
+// 	users := db.Find("users", "Name", "Van")
+func (m *MemDb) Find(table, field string, value interface{}) (records []interface{}) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, record := range m.tables[table] {
+		if matches(record, field, []interface{}{value}) {
+			records = append(records, clone.Copy(record))
+		}
+	}
+
+	return
+}
+
+// All returns copies of all the records in table, in the order of creation.
+func (m *MemDb) All(table string) (records []interface{}) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, record := range m.tables[table] {
+		records = append(records, clone.Copy(record))
+	}
+
+	return
+}
+
+// Count returns the number of records in table.
+func (m *MemDb) Count(table string) int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return len(m.tables[table])
+}
+
+// Tables returns names of the tables having records, sorted.
+func (m *MemDb) Tables() (tables []string) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for table, records := range m.tables {
+		if len(records) > 0 {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+
+	return
+}
+
+// matches checks whether field of record equals any of values.
+func matches(record interface{}, field string, values []interface{}) bool {
+	f := reflect.ValueOf(record).FieldByName(field)
+	if !f.IsValid() || !f.CanInterface() {
+		return false
+	}
+	for _, value := range values {
+		if reflect.DeepEqual(f.Interface(), value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,67 @@
+package memdriver
+
+import (
+	"github.com/bom-d-van/gogetter/drivertest"
+	. "launchpad.net/gocheck"
+	"testing"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type MemDbSuite struct{ *MemDb }
+
+var _ = Suite(&MemDbSuite{})
+
+var _ = Suite(&drivertest.Suite{New: func(c *C) *drivertest.Fixture {
+	db := NewMemDb()
+	return &drivertest.Fixture{
+		Db:    db,
+		Count: func(table string) (int, error) { return db.Count(table), nil },
+	}
+}})
+
+type User struct {
+	Id     int
+	Name   string
+	Places []string
+}
+
+func (s *MemDbSuite) SetUpTest(c *C) {
+	s.MemDb = NewMemDb()
+}
+
+func (s *MemDbSuite) TestCreateAndRemove(c *C) {
+	user := &User{Id: 1, Name: "Van", Places: []string{"Arles"}}
+	err := s.Create("users", user, User{Id: 2, Name: "Gogh"}, User{Id: 3, Name: "Gogh"})
+	c.Check(err, Equals, nil)
+	c.Check(s.Count("users"), Equals, 3)
+
+	// Records are copies
+	user.Places[0] = "Paris"
+	found := s.Find("users", "Id", 1)
+	c.Assert(found, HasLen, 1)
+	c.Check(found[0], DeepEquals, User{Id: 1, Name: "Van", Places: []string{"Arles"}})
+	found[0].(User).Places[0] = "Paris"
+	c.Check(s.All("users")[0].(User).Places, DeepEquals, []string{"Arles"})
+	c.Check(s.Find("users", "Name", "Gogh"), HasLen, 2)
+	c.Check(s.Find("users", "Unknown", "Gogh"), HasLen, 0)
+
+	c.Check(s.Remove("users", "Id", 1, 3), Equals, nil)
+	c.Check(s.All("users"), DeepEquals, []interface{}{User{Id: 2, Name: "Gogh"}})
+	c.Check(s.Remove("nothings", "Id", 1), Equals, nil)
+}
+
+func (s *MemDbSuite) TestCreateErrors(c *C) {
+	c.Check(s.Create("users", 1), ErrorMatches, "memdriver: can't create int in users")
+	c.Check(s.Count("users"), Equals, 0)
+}
+
+func (s *MemDbSuite) TestTruncate(c *C) {
+	s.Create("users", User{Id: 1})
+	s.Create("posts", User{Id: 1})
+	s.Create("tags", User{Id: 1})
+	c.Check(s.Tables(), DeepEquals, []string{"posts", "tags", "users"})
+	c.Check(s.Truncate("users", "posts"), Equals, nil)
+	c.Check(s.Tables(), DeepEquals, []string{"tags"})
+}
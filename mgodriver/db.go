@@ -1,16 +1,26 @@
 package mgodriver
 
 import (
+	"github.com/bom-d-van/gogetter"
 	"labix.org/v2/mgo"
 	"labix.org/v2/mgo/bson"
+	"reflect"
+	"strings"
+	"sync"
 )
 
 type MongoDb struct {
-	db *mgo.Database
+	db              *mgo.Database
+	dropCollections bool
+
+	// types of the documents created in collections, for mapping id fields
+	// into keys.
+	mutex sync.Mutex
+	types map[string]reflect.Type
 }
 
 func NewMongoDb(db *mgo.Database) (mdb *MongoDb) {
-	return &MongoDb{db: db}
+	return &MongoDb{db: db, types: map[string]reflect.Type{}}
 }
 
 func (m *MongoDb) Create(col string, docs ...interface{}) (err error) {
@@ -18,15 +28,107 @@ func (m *MongoDb) Create(col string, docs ...interface{}) (err error) {
 		return
 	}
 
+	t := reflect.TypeOf(docs[0])
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	m.mutex.Lock()
+	m.types[col] = t
+	m.mutex.Unlock()
+
 	return m.db.C(col).Insert(docs...)
 }
 
-// Note: If the id field is "Id", MongoDb will convert it into "_id", which is the real id of documents in mongodb.
+var objectIdType = reflect.TypeOf(bson.ObjectId(""))
+
+// NewObjectId is a gogetter.IdGenerator of bson.ObjectIds:
+//
+// 	gogetter.RegisterIdGenerator(reflect.TypeOf(bson.ObjectId("")), mgodriver.NewObjectId)
+func NewObjectId() gogetter.Dream {
+	return bson.NewObjectId()
+}
+
+// Store implements gogetter.Storer, it assigns new ObjectIds to the empty
+// ObjectId fields stored as "_id" of docs, i.e. what mongo would do, before
+// creating them, so that dreams could be destroyed by their ids.
+func (m *MongoDb) Store(col string, docs ...interface{}) (err error) {
+	for _, doc := range docs {
+		v := reflect.ValueOf(doc)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key := strings.Split(field.Tag.Get("bson"), ",")[0]
+			if key == "_id" && field.Type == objectIdType && v.Field(i).String() == "" && v.Field(i).CanSet() {
+				v.Field(i).Set(reflect.ValueOf(bson.NewObjectId()))
+			}
+		}
+	}
+
+	return m.Create(col, docs...)
+}
+
+// Note: idField is mapped into its key by the bson tag of the field of documents
+// created in the collection, or the lower case of idField like bson does. If
+// nothing is created in the collection by MongoDb, "Id" is converted into "_id",
+// which is the real id of documents in mongodb.
 // If this conversion doesn't fit in your cases, feel free to create you own mongo db driver.
 func (m *MongoDb) Remove(col string, idField string, ids ...interface{}) (err error) {
-	if idField == "Id" {
-		idField = "_id"
+	if len(ids) == 0 {
+		return
+	}
+
+	_, err = m.db.C(col).RemoveAll(bson.M{m.key(col, idField): bson.M{"$in": ids}})
+	return
+}
+
+func (m *MongoDb) key(col, field string) string {
+	m.mutex.Lock()
+	t := m.types[col]
+	m.mutex.Unlock()
+
+	if t == nil || t.Kind() != reflect.Struct {
+		if field == "Id" {
+			return "_id"
+		}
+		return strings.ToLower(field)
 	}
-	_, err = m.db.C(col).RemoveAll(bson.M{idField: bson.M{"$in": ids}})
+
+	if f, ok := t.FieldByName(field); ok {
+		if key := strings.Split(f.Tag.Get("bson"), ",")[0]; key != "" && key != "-" {
+			return key
+		}
+	}
+
+	return strings.ToLower(field)
+}
+
+// By default, Truncate removes all documents of the collections, keeping their
+// indexes. SetDropCollections makes it drop the collections instead, which is
+// faster for large collections.
+func (m *MongoDb) SetDropCollections(drop bool) {
+	m.dropCollections = drop
+}
+
+func (m *MongoDb) Truncate(cols ...string) (err error) {
+	for _, col := range cols {
+		if m.dropCollections {
+			err = m.db.C(col).DropCollection()
+			// Collections not created yet
+			if e, ok := err.(*mgo.QueryError); ok && e.Message == "ns not found" {
+				err = nil
+			}
+		} else {
+			_, err = m.db.C(col).RemoveAll(nil)
+		}
+		if err != nil {
+			return
+		}
+	}
+
 	return
 }
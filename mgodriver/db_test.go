@@ -1,10 +1,12 @@
 package mgodriver
 
 import (
+	"github.com/bom-d-van/gogetter/drivertest"
 	"labix.org/v2/mgo"
 	"labix.org/v2/mgo/bson"
 	. "launchpad.net/gocheck"
 	"testing"
+	"time"
 )
 
 // Hook up gocheck into the "go test" runner.
@@ -14,33 +16,44 @@ type MongoDbSuite struct{ *MongoDb }
 
 var _ = Suite(&MongoDbSuite{})
 
+var _ = Suite(&drivertest.Suite{New: func(c *C) *drivertest.Fixture {
+	session, err := mgo.DialWithTimeout("localhost", time.Second)
+	if err != nil {
+		c.Skip("MongoDB is not available: " + err.Error())
+	}
+	db := session.DB("gogetter")
+	_, err = db.C(drivertest.Table).RemoveAll(nil)
+	c.Assert(err, Equals, nil)
+	return &drivertest.Fixture{
+		Db:    NewMongoDb(db),
+		Count: func(col string) (int, error) { return db.C(col).Count() },
+		Close: session.Close,
+	}
+}})
+
 type User struct {
 	Id   bson.ObjectId `bson:"_id"`
 	Name string
 }
 
 func (s *MongoDbSuite) SetUpSuite(c *C) {
-	// session, err := mgo.Dial("localhost")
-	// c.Check(err, Equals, nil)
-	// s.MongoDb = NewMongoDb(session.DB("gogetter"))
-	s.MongoDb = NewMongoDb(getTestDb())
-	_, err := s.db.C("mongousers").RemoveAll(nil)
+	session, err := mgo.DialWithTimeout("localhost", time.Second)
+	if err != nil {
+		c.Skip("MongoDB is not available: " + err.Error())
+	}
+	s.MongoDb = NewMongoDb(session.DB("gogetter"))
+	_, err = s.db.C("mongousers").RemoveAll(nil)
 	c.Check(err, Equals, nil)
 }
 
 func (s *MongoDbSuite) TearDownSuite(c *C) {
+	if s.MongoDb == nil {
+		return
+	}
 	_, err := s.db.C("mongousers").RemoveAll(nil)
 	c.Check(err, Equals, nil)
 }
 
-func getTestDb() *mgo.Database {
-	session, err := mgo.Dial("localhost")
-	if err != nil {
-		panic(err)
-	}
-	return session.DB("gogetter")
-}
-
 func (s *MongoDbSuite) TestDbOperation(c *C) {
 	user := User{Id: bson.NewObjectId(), Name: "a user"}
 	err := s.Create("mongousers", user)
@@ -49,8 +62,41 @@ func (s *MongoDbSuite) TestDbOperation(c *C) {
 	c.Check(err, Equals, nil)
 	c.Check(count, Equals, 1)
 
-	s.Remove("mongousers", user.Id)
+	s.Remove("mongousers", "Id", user.Id)
 	count, err = s.db.C("mongousers").Find(bson.M{"name": "a user"}).Count()
 	c.Check(err, Equals, nil)
 	c.Check(count, Equals, 0)
 }
+
+func (s *MongoDbSuite) TestStore(c *C) {
+	user := &User{Name: "stored user"}
+	given := bson.NewObjectId()
+	err := s.Store("mongousers", user, &User{Id: given, Name: "stored user"})
+	c.Check(err, Equals, nil)
+	c.Check(user.Id.Valid(), Equals, true)
+	count, err := s.db.C("mongousers").Find(bson.M{"_id": bson.M{"$in": []bson.ObjectId{user.Id, given}}}).Count()
+	c.Check(err, Equals, nil)
+	c.Check(count, Equals, 2)
+
+	c.Check(s.Remove("mongousers", "Id", user.Id, given), Equals, nil)
+}
+
+func (s *MongoDbSuite) TestTruncate(c *C) {
+	err := s.Create("mongousers", User{Id: bson.NewObjectId()}, User{Id: bson.NewObjectId()})
+	c.Check(err, Equals, nil)
+	c.Check(s.Truncate("mongousers", "mongonothings"), Equals, nil)
+	count, err := s.db.C("mongousers").Count()
+	c.Check(err, Equals, nil)
+	c.Check(count, Equals, 0)
+
+	s.SetDropCollections(true)
+	defer s.SetDropCollections(false)
+	err = s.Create("mongousers", User{Id: bson.NewObjectId()})
+	c.Check(err, Equals, nil)
+	c.Check(s.Truncate("mongousers", "mongonothings"), Equals, nil)
+	names, err := s.db.CollectionNames()
+	c.Check(err, Equals, nil)
+	for _, name := range names {
+		c.Check(name, Not(Equals), "mongousers")
+	}
+}
@@ -0,0 +1,239 @@
+package gogetter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type pathStep struct {
+	field   string
+	index   string
+	isIndex bool
+}
+
+// Lesson keys are field paths, which are field names joined by dots, with
+// indexes of slices and arrays or keys of maps in square brackets, e.g.:
+//
+// 	"Name"
+// 	"Dream.Title"
+// 	"Address.Geo.Lat"
+// 	"VisitedPlaces[1]"
+// 	"Meta[owner].Name"
+//
+// Nil pointers on the path are allocated, slices are grown when the index is
+// out of range, and fields of embedded structs could be used directly.
+func parseFieldPath(path string) (steps []pathStep, err error) {
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			if i == 0 || i == len(path)-1 || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, fmt.Errorf("invalid field path %q", path)
+			}
+			i++
+		case '[':
+			if i == 0 {
+				return nil, fmt.Errorf("invalid field path %q", path)
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in field path %q", path)
+			}
+			steps = append(steps, pathStep{index: path[i+1 : i+end], isIndex: true})
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[]")
+			if end < 0 {
+				end = len(path) - i
+			} else if path[i+end] == ']' {
+				return nil, fmt.Errorf("invalid field path %q", path)
+			}
+			steps = append(steps, pathStep{field: path[i : i+end]})
+			i += end
+		}
+	}
+
+	if len(steps) == 0 {
+		err = fmt.Errorf("empty field path")
+	}
+
+	return
+}
+
+// setFieldPath finds the value standing for path in v, and calls set with it.
+// Values in maps are not addressable, so set is used to write them back after
+// the modification. Field names are looked up in tag first if it's not empty.
+func setFieldPath(v reflect.Value, path, tag string, set func(field reflect.Value) error) (err error) {
+	steps, err := parseFieldPath(path)
+	if err != nil {
+		return
+	}
+
+	return walkFieldPath(v, steps, tag, set)
+}
+
+func walkFieldPath(v reflect.Value, steps []pathStep, tag string, set func(field reflect.Value) error) (err error) {
+	if len(steps) == 0 {
+		return set(v)
+	}
+
+	if v, err = allocElem(v); err != nil {
+		return
+	}
+	step := steps[0]
+	if !step.isIndex {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("can't find field %s in %s", step.field, v.Type())
+		}
+		field, ok := lookupField(v.Type(), step.field, tag)
+		if !ok {
+			return &UnknownFieldError{
+				Struct:     v.Type(),
+				Field:      step.field,
+				Suggestion: suggestField(v.Type(), step.field, tag),
+			}
+		}
+		if field.PkgPath != "" {
+			return &UnexportedFieldError{Struct: v.Type(), Field: field.Name}
+		}
+		for i, x := range field.Index {
+			if i > 0 {
+				if v, err = allocElem(v); err != nil {
+					return
+				}
+			}
+			v = v.Field(x)
+		}
+
+		return walkFieldPath(v, steps[1:], tag, set)
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(step.index)
+		if err != nil || i < 0 {
+			return fmt.Errorf("invalid index [%s] of %s", step.index, v.Type())
+		}
+		if i >= v.Len() {
+			if v.Kind() == reflect.Array {
+				return fmt.Errorf("index [%d] out of range of %s", i, v.Type())
+			}
+			grown := reflect.MakeSlice(v.Type(), i+1, i+1)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+
+		return walkFieldPath(v.Index(i), steps[1:], tag, set)
+	case reflect.Map:
+		key, err := parseMapKey(step.index, v.Type().Key())
+		if err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if old := v.MapIndex(key); old.IsValid() {
+			elem.Set(old)
+		}
+		if err = walkFieldPath(elem, steps[1:], tag, set); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+
+		return nil
+	}
+
+	return fmt.Errorf("%s can't be indexed by [%s]", v.Type(), step.index)
+}
+
+// lookupField finds the field by its name in the struct tag, and falls back to
+// the Go name if nothing is found or tag is empty.
+func lookupField(t reflect.Type, name, tag string) (field reflect.StructField, ok bool) {
+	if tag != "" {
+		if field, ok = lookupTaggedField(t, name, tag, nil); ok {
+			return
+		}
+	}
+
+	return t.FieldByName(name)
+}
+
+// lookupTaggedField looks up fields of embedded structs without tag names too,
+// like the way encoding/json does.
+func lookupTaggedField(t reflect.Type, name, tag string, index []int) (field reflect.StructField, ok bool) {
+	embedded := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field = t.Field(i)
+		field.Index = append(append([]int{}, index...), i)
+		tagName := strings.Split(field.Tag.Get(tag), ",")[0]
+		if tagName == name && tagName != "-" {
+			return field, true
+		}
+		if field.Anonymous && tagName == "" {
+			embedded = append(embedded, field)
+		}
+	}
+
+	for _, e := range embedded {
+		et := e.Type
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if et.Kind() != reflect.Struct {
+			continue
+		}
+		if field, ok = lookupTaggedField(et, name, tag, e.Index); ok {
+			return
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// allocElem dereferences v if it's a pointer, nil pointers are allocated.
+func allocElem(v reflect.Value) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return v, fmt.Errorf("can't allocate nil pointer of unexported embedded %s", v.Type())
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	return v, nil
+}
+
+func parseMapKey(s string, t reflect.Type) (key reflect.Value, err error) {
+	key = reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		key.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, t.Bits())
+		key.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, t.Bits())
+		key.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		key.SetFloat(f)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		key.SetBool(b)
+	default:
+		return key, fmt.Errorf("unsupported map key type %s", t)
+	}
+	if err != nil {
+		err = fmt.Errorf("invalid map key [%s] of %s", s, t)
+	}
+
+	return
+}
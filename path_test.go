@@ -0,0 +1,155 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type Geo struct {
+	Lat, Lng float64
+}
+
+type Address struct {
+	City string
+	Geo  *Geo
+}
+
+type Timestamps struct {
+	CreatedBy string
+}
+
+type Place struct {
+	*Timestamps
+	Name    string
+	Address Address
+	Tags    []string
+	Meta    map[string]*DreamS
+	Scores  map[int]int
+}
+
+func init() {
+	SetGoal("Place", func() Dream {
+		return Place{
+			Name:    "Home",
+			Address: Address{City: "Shanghai"},
+			Tags:    []string{"warm"},
+		}
+	})
+}
+
+func (s *GoGetterSuite) TestNestedFieldPaths(c *C) {
+	placeI, err := Grow("Place", Lesson{
+		"Address.Geo.Lat":   31.2,
+		"Tags[2]":           "sweet",
+		"Meta[owner]":       &DreamS{Title: "Owner"},
+		"Meta[guest].Title": "Guest",
+		"Scores[1]":         100,
+		"CreatedBy":         "gogetter",
+	})
+	c.Check(err, Equals, nil)
+	place := placeI.(Place)
+	c.Check(place.Name, Equals, "Home")
+	c.Check(place.Address.City, Equals, "Shanghai")
+	c.Check(place.Address.Geo.Lat, Equals, 31.2)
+	c.Check(place.Tags, DeepEquals, []string{"warm", "", "sweet"})
+	c.Check(place.Meta["owner"].Title, Equals, "Owner")
+	c.Check(place.Meta["guest"].Title, Equals, "Guest")
+	c.Check(place.Scores[1], Equals, 100)
+	c.Check(place.CreatedBy, Equals, "gogetter")
+}
+
+func (s *GoGetterSuite) TestNestedFieldPathsMergeIntoGoal(c *C) {
+	usersI, err := Grow("*User", Lesson{
+		"Dream.Content":    "Content",
+		"VisitedPlaces[1]": "Shanghai",
+	}, Lesson{
+		"Dream":         &DreamS{Title: "New Dream"},
+		"Dream.Content": "Content",
+	})
+	c.Check(err, Equals, nil)
+	users := usersI.([]*User)
+	c.Check(users[0].Dream.Title, Equals, "My Dream")
+	c.Check(users[0].Dream.Content, Equals, "Content")
+	c.Check(users[0].VisitedPlaces, DeepEquals, []string{"New York City", "Shanghai"})
+	c.Check(users[1].Dream.Title, Equals, "New Dream")
+	c.Check(users[1].Dream.Content, Equals, "Content")
+}
+
+func (s *GoGetterSuite) TestNestedFieldPathsInLessonValues(c *C) {
+	dream := &DreamS{Title: "Shared"}
+	lesson := Lesson{"Dream": dream, "Dream.Content": "Content"}
+	usersI, err := Grow("*User", lesson, lesson)
+	c.Check(err, Equals, nil)
+	users := usersI.([]*User)
+	c.Check(*users[0].Dream, Equals, DreamS{Title: "Shared", Content: "Content"})
+	c.Check(users[0].Dream == users[1].Dream, Equals, false)
+	c.Check(*dream, Equals, DreamS{Title: "Shared"})
+
+	// Inspirations in overridden values are dropped
+	reg := NewRegistry(DefaultRegistry())
+	reg.AscendGoal("Inspired User", "User", func() Lesson {
+		return Lesson{"Dream.Title": Inspiration(func(Dream, int) Dream { return "Inspired" })}
+	})
+	gg := NewGoGetter(nil, reg)
+	userI, err := gg.Grow("Inspired User")
+	c.Check(err, Equals, nil)
+	c.Check(userI.(User).Dream.Title, Equals, "Inspired")
+	userI, err = gg.Grow("Inspired User", Lesson{"Dream": &DreamS{Title: "Override"}})
+	c.Check(err, Equals, nil)
+	c.Check(userI.(User).Dream.Title, Equals, "Override")
+	userI, err = gg.Grow("Inspired User", Lesson{"Dream": &DreamS{Title: "Override"}, "Dream.Title": "Title"})
+	c.Check(err, Equals, nil)
+	c.Check(userI.(User).Dream.Title, Equals, "Title")
+}
+
+func (s *GoGetterSuite) TestInvalidFieldPaths(c *C) {
+	for _, path := range []string{"", ".Name", "Name.", "Name..Title", "[1]", "Tags[1", "Tags]"} {
+		_, err := parseFieldPath(path)
+		c.Check(err, Not(Equals), nil, Commentf(path))
+	}
+
+	_, err := Grow("Place", Lesson{"Address.Geo[1]": 1.0})
+	c.Check(err, Not(Equals), nil)
+	_, err = Grow("User", Lesson{"ThereGreatIdeas[3]": "Sleep"})
+	c.Check(err, Not(Equals), nil)
+}
+
+type Fixture struct {
+	Id        string   `bson:"_id" json:"id"`
+	FirstName string   `bson:"first_name" json:"firstName,omitempty"`
+	Ignored   string   `json:"-"`
+	Fixture   *Fixture `bson:"fixture"`
+	*Timestamps
+}
+
+func init() {
+	SetGoal("Fixture", func() Dream { return Fixture{} })
+}
+
+func (s *GoGetterSuite) TestLessonKeysInTags(c *C) {
+	gg := NewGoGetter(nil)
+	gg.SetLessonTag("bson")
+	fixtureI, err := gg.Grow("Fixture", Lesson{
+		"_id":                "id",
+		"first_name":         "Van",
+		"fixture.first_name": "Nested",
+		"CreatedBy":          "gogetter",
+	})
+	c.Check(err, Equals, nil)
+	fixture := fixtureI.(Fixture)
+	c.Check(fixture.Id, Equals, "id")
+	c.Check(fixture.FirstName, Equals, "Van")
+	c.Check(fixture.Fixture.FirstName, Equals, "Nested")
+	c.Check(fixture.CreatedBy, Equals, "gogetter")
+
+	gg.SetLessonTag("json")
+	fixtureI, err = gg.Grow("Fixture", Lesson{"firstName": "Van", "Id": "id"})
+	c.Check(err, Equals, nil)
+	c.Check(fixtureI.(Fixture).FirstName, Equals, "Van")
+	c.Check(fixtureI.(Fixture).Id, Equals, "id")
+	_, err = gg.Grow("Fixture", Lesson{"-": "Ignored"})
+	c.Check(err, Not(Equals), nil)
+
+	gg.SetLessonTag("")
+	_, err = gg.Grow("Fixture", Lesson{"first_name": "Van"})
+	c.Check(err, Not(Equals), nil)
+}
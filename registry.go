@@ -0,0 +1,335 @@
+package gogetter
+
+import (
+	"bitbucket.org/pkg/inflect"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Registry holds goals and everything registered for them, e.g. table names,
+// traits, hooks and associations. Package functions like SetGoal and AscendGoal
+// register in the default registry, which is used by GoGetters created without
+// a registry.
+//
+// A registry created with a parent falls back to the parent for anything not
+// registered in itself, so a test could override goals without affecting the
+// others:
+//
+// 	reg := gogetter.NewRegistry(gogetter.DefaultRegistry())
+// 	reg.SetGoal("User", func() gogetter.Dream { return User{Name: "Guest"} })
+// 	gg := gogetter.NewGoGetter(db, reg)
+//
+// Traits, sequences, converters and id generators registered in the registry
+// take precedence over the ones of its parent, hooks, associations and
+// dependencies are added to the ones of its parent.
+type Registry struct {
+	parent *Registry
+
+	// mutex is never held while calling goals, lessons or hooks, so they could
+	// register goals too.
+	mutex sync.RWMutex
+
+	goals          map[string]Goal
+	parents        map[string]*parentGoal
+	tableNames     map[string]string
+	defaultTableId string
+
+	// tables and idFields cache the resolved table names and id fields, they
+	// are never inherited, for that goals might be overridden.
+	tables   map[string]string
+	idFields map[string]string
+
+	sequences       map[string]Lesson
+	forkedSequences map[string]bool
+	traits          map[string]map[string]func() Lesson
+	hooks           map[string]map[hookMoment][]Hook
+	associations    map[string][]*association
+	dependencies    map[string][]string
+	converters      map[reflect.Type]Converter
+
+	idGenerators     map[string]IdGenerator
+	typeIdGenerators map[reflect.Type]IdGenerator
+}
+
+var defaultRegistry = NewRegistry(nil)
+
+// NewRegistry creates an empty registry, which falls back to parent if it's
+// not nil.
+func NewRegistry(parent *Registry) *Registry {
+	return &Registry{
+		parent:          parent,
+		goals:           map[string]Goal{},
+		parents:         map[string]*parentGoal{},
+		tableNames:      map[string]string{},
+		tables:          map[string]string{},
+		idFields:        map[string]string{},
+		sequences:       map[string]Lesson{},
+		forkedSequences: map[string]bool{},
+		traits:          map[string]map[string]func() Lesson{},
+		hooks:           map[string]map[hookMoment][]Hook{},
+		associations:    map[string][]*association{},
+		dependencies:    map[string][]string{},
+		converters:      map[reflect.Type]Converter{},
+
+		idGenerators:     map[string]IdGenerator{},
+		typeIdGenerators: map[reflect.Type]IdGenerator{},
+	}
+}
+
+// DefaultRegistry returns the registry used by the package functions.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Clone returns a copy of the registry and its parents, registering in either
+// of them doesn't affect the other.
+func (r *Registry) Clone() *Registry {
+	var parent *Registry
+	if r.parent != nil {
+		parent = r.parent.Clone()
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	c := NewRegistry(parent)
+	c.defaultTableId = r.defaultTableId
+	for k, v := range r.goals {
+		c.goals[k] = v
+	}
+	for k, v := range r.parents {
+		c.parents[k] = v
+	}
+	for k, v := range r.tableNames {
+		c.tableNames[k] = v
+	}
+	for k, v := range r.tables {
+		c.tables[k] = v
+	}
+	for k, v := range r.idFields {
+		c.idFields[k] = v
+	}
+	for k, v := range r.sequences {
+		lesson := Lesson{}
+		for field, seq := range v {
+			lesson[field] = seq
+		}
+		c.sequences[k] = lesson
+	}
+	for k, v := range r.forkedSequences {
+		c.forkedSequences[k] = v
+	}
+	for k, v := range r.traits {
+		traits := map[string]func() Lesson{}
+		for trait, lesson := range v {
+			traits[trait] = lesson
+		}
+		c.traits[k] = traits
+	}
+	for k, v := range r.hooks {
+		hooks := map[hookMoment][]Hook{}
+		for moment, hs := range v {
+			hooks[moment] = append([]Hook{}, hs...)
+		}
+		c.hooks[k] = hooks
+	}
+	for k, v := range r.associations {
+		c.associations[k] = append([]*association{}, v...)
+	}
+	for k, v := range r.dependencies {
+		c.dependencies[k] = append([]string{}, v...)
+	}
+	for k, v := range r.converters {
+		c.converters[k] = v
+	}
+	for k, v := range r.idGenerators {
+		c.idGenerators[k] = v
+	}
+	for k, v := range r.typeIdGenerators {
+		c.typeIdGenerators[k] = v
+	}
+
+	return c
+}
+
+// SetGoal saves the goal in the registry, then all GoGetters using the registry
+// could share the same set of goals.
+//
+// 	Note:
+// 	1. Leading asterisk (*) in name is saved for gogetter.
+// 	2. The return value of goal must be a Struct, map or anything else is not supported.
+func (r *Registry) SetGoal(name string, goal Goal) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.goals[name] = goal
+	// A goal set in a child registry is not ascended from the parent goal
+	// that it overrides.
+	r.parents[name] = nil
+	delete(r.tables, name)
+	delete(r.idFields, name)
+}
+
+func (r *Registry) GetGoal(name string) Goal {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		goal, ok := reg.goals[name]
+		reg.mutex.RUnlock()
+		if ok {
+			return goal
+		}
+	}
+
+	return nil
+}
+
+// By default, GetTableName will use parent's table name.
+func (r *Registry) AscendGoal(child, parent string, lesson func() Lesson) {
+	goal := r.GetGoal(parent)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.goals[child] = goal
+	r.parents[child] = &parentGoal{parent, lesson}
+	delete(r.tables, child)
+	delete(r.idFields, child)
+}
+
+func (r *Registry) getParentGoal(name string) *parentGoal {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		pg, ok := reg.parents[name]
+		reg.mutex.RUnlock()
+		if ok {
+			return pg
+		}
+	}
+
+	return nil
+}
+
+// getGoalChain returns name and all its parents.
+func (r *Registry) getGoalChain(name string) (chain []string) {
+	chain = append(chain, name)
+	for {
+		pg := r.getParentGoal(name)
+		if pg == nil {
+			return
+		}
+		name = pg.parent
+		chain = append(chain, name)
+	}
+}
+
+// Setting table name is optional, if table name is not specifically setted, gogetter
+// will use the pluralization and lower case form of the name as table name, it will
+// also replace all spaces with underscores.
+func (r *Registry) SetTableName(name, table string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tableNames[name] = table
+}
+
+func (r *Registry) GetTableName(name string) (table string, err error) {
+	for reg := r; reg != nil; reg = reg.parent {
+		var ok bool
+		reg.mutex.RLock()
+		table, ok = reg.tableNames[name]
+		if !ok && reg == r {
+			table, ok = reg.tables[name]
+		}
+		reg.mutex.RUnlock()
+		if ok {
+			return
+		}
+	}
+
+	if r.GetGoal(name) == nil {
+		return "", ErrGetterNotExist
+	}
+
+	if pg := r.getParentGoal(name); pg != nil {
+		table, err = r.GetTableName(pg.parent)
+		if err != nil {
+			return
+		}
+	} else {
+		table = inflect.Pluralize(strings.ToLower(name))
+		table = strings.Replace(table, " ", "_", -1)
+	}
+
+	r.mutex.Lock()
+	r.tables[name] = table
+	r.mutex.Unlock()
+
+	return
+}
+
+// Table Id is used when gogetter is trying remove records from table, using a simple
+// sql/mongo statement to remove the data.
+// Default Table Id is "Id", its value must be comparable via reflect.DeepEqual.
+func (r *Registry) SetDefaultTableId(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.defaultTableId = name
+	r.idFields = map[string]string{}
+}
+
+func (r *Registry) getDefaultTableId() string {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		id := reg.defaultTableId
+		reg.mutex.RUnlock()
+		if id != "" {
+			return id
+		}
+	}
+
+	return "Id"
+}
+
+// getDreamIdField returns the id field of dreams of the goal, whose type is
+// dType, which is passed in instead of calling the Goal, for that Goals could
+// have side effects, e.g. advancing sequences with Next.
+func (r *Registry) getDreamIdField(name string, dType reflect.Type) (id string) {
+	var ok bool
+	r.mutex.RLock()
+	id, ok = r.idFields[name]
+	r.mutex.RUnlock()
+	if ok {
+		return
+	}
+
+	for {
+		// TODO: refactor
+		if dType.Kind() == reflect.Ptr {
+			dType = dType.Elem()
+		} else {
+			break
+		}
+	}
+
+	for i := 0; i < dType.NumField(); i++ {
+		field := dType.Field(i)
+		if hasGogetterTag(field, "id") {
+			id = field.Name
+			break
+		}
+	}
+
+	if id == "" {
+		tableId := r.getDefaultTableId()
+		if _, ok := dType.FieldByName(tableId); ok {
+			id = tableId
+		}
+	}
+
+	r.mutex.Lock()
+	r.idFields[name] = id
+	r.mutex.Unlock()
+	return
+}
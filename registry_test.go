@@ -0,0 +1,106 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+func (s *GoGetterSuite) TestRegistryFallsBackToParent(c *C) {
+	reg := NewRegistry(DefaultRegistry())
+	reg.SetGoal("User", func() Dream { return User{Name: "guest"} })
+	reg.SetTrait("User", "admin", func() Lesson { return Lesson{"VisitedPlaces": []string{"admin"}} })
+	gg := NewGoGetter(nil, reg)
+
+	user, err := gg.Grow("User:admin")
+	c.Check(err, Equals, nil)
+	c.Check(user.(User).Name, Equals, "guest")
+	c.Check(user.(User).VisitedPlaces, DeepEquals, []string{"admin"})
+
+	superUser, err := gg.Grow("Super User")
+	c.Check(err, Equals, nil)
+	c.Check(superUser.(User).Name, Equals, "Super User")
+
+	table, err := reg.GetTableName("Super User")
+	c.Check(err, Equals, nil)
+	c.Check(table, Equals, "users")
+
+	// The default registry is not affected
+	user, err = Grow("User")
+	c.Check(err, Equals, nil)
+	c.Check(user.(User).Name, Equals, "name")
+	_, err = Grow("User:admin")
+	c.Check(err, Not(Equals), nil)
+}
+
+func (s *GoGetterSuite) TestRegistryOverridesAscendedGoal(c *C) {
+	reg := NewRegistry(DefaultRegistry())
+	reg.SetGoal("Super User", func() Dream { return User{Name: "not ascended"} })
+	reg.SetTableName("Super User", "super_users")
+	gg := NewGoGetter(nil, reg)
+
+	user, err := gg.Grow("Super User")
+	c.Check(err, Equals, nil)
+	c.Check(user.(User).Name, Equals, "not ascended")
+	table, _ := reg.GetTableName("Super User")
+	c.Check(table, Equals, "super_users")
+	table, _ = GetTableName("Super User")
+	c.Check(table, Equals, "users")
+}
+
+func (s *GoGetterSuite) TestLayeredHooks(c *C) {
+	calls := []string{}
+	parent := NewRegistry(nil)
+	parent.SetGoal("Article", func() Dream { return Article{} })
+	parent.AfterBuild("Article", func(dream Dream) error {
+		calls = append(calls, "parent")
+		return nil
+	})
+	child := NewRegistry(parent)
+	child.AfterBuild("Article", func(dream Dream) error {
+		calls = append(calls, "child")
+		return nil
+	})
+
+	_, err := NewGoGetter(nil, child).Grow("Article")
+	c.Check(err, Equals, nil)
+	c.Check(calls, DeepEquals, []string{"parent", "child"})
+
+	calls = nil
+	_, err = NewGoGetter(nil, parent).Grow("Article")
+	c.Check(err, Equals, nil)
+	c.Check(calls, DeepEquals, []string{"parent"})
+}
+
+func (s *GoGetterSuite) TestCloneRegistry(c *C) {
+	reg := NewRegistry(nil)
+	reg.SetGoal("Member", func() Dream { return Member{} })
+	reg.SetSequence("Member", "Email", "member-%d@example.com")
+
+	clone := reg.Clone()
+	clone.SetSequence("Member", "Email", "clone-%d@example.com")
+	clone.SetGoal("Clone", func() Dream { return Member{} })
+	reg.SetTableName("Member", "people")
+
+	member, err := NewGoGetter(nil, reg).Grow("Member")
+	c.Check(err, Equals, nil)
+	c.Check(member.(Member).Email, Equals, "member-1@example.com")
+	member, err = NewGoGetter(nil, clone).Grow("Member")
+	c.Check(err, Equals, nil)
+	c.Check(member.(Member).Email, Equals, "clone-1@example.com")
+
+	c.Check(reg.GetGoal("Clone"), IsNil)
+	table, _ := clone.GetTableName("Member")
+	c.Check(table, Equals, "members")
+}
+
+// Registries without a parent still destroy dreams by their "Id" fields.
+func (s *GoGetterSuite) TestIsolatedRegistryDefaultTableId(c *C) {
+	reg := NewRegistry(nil)
+	reg.SetGoal("Badge", func() Dream { return Badge{Id: 1} })
+	db := newFakeDb()
+	gg := NewGoGetter(db, reg)
+
+	_, err := gg.Realize("Badge")
+	c.Check(err, Equals, nil)
+	c.Check(gg.Apocalypse(), Equals, nil)
+	c.Check(db.removed["badges"], DeepEquals, []interface{}{1})
+}
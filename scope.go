@@ -0,0 +1,168 @@
+package gogetter
+
+// Scope creates a child GoGetter sharing the database, registry, options and
+// sequence counters of the GoGetter, but tracking its own dreams, so that
+// Apocalypse and Close of the scope only destroy what it made. Scopes could be
+// nested, e.g. for subtests:
+//
+// 	func TestPosts(t *testing.T) {
+// 		scope := getter.Scope()
+// 		defer scope.Close()
+// 		author, _ := scope.Realize("*User")
+//
+// 		t.Run("Draft", func(t *testing.T) {
+// 			sub := scope.Scope()
+// 			defer sub.Close() // author is kept for other subtests
+// 			sub.Realize("Post", gogetter.Lesson{"AuthorId": author.(*User).Id})
+// 		})
+// 	}
+func (gg *GoGetter) Scope() *GoGetter {
+	child := NewGoGetter(gg.getDb(), gg.registry)
+	child.options = gg.getOptions()
+	child.sequences = gg.sequences
+	child.parent = gg
+
+	gg.mutex.Lock()
+	gg.children = append(gg.children, child)
+	gg.mutex.Unlock()
+
+	return child
+}
+
+// Close destroys dreams made by the GoGetter and its open scopes, the scopes
+// created later are closed first. A closed scope is detached from its parent,
+// but it could still be used, as a new scope of nobody.
+func (gg *GoGetter) Close() (err error) {
+	gg.mutex.Lock()
+	children := append([]*GoGetter{}, gg.children...)
+	gg.mutex.Unlock()
+
+	apocalypseErr := &ApocalypseError{}
+	for i := len(children) - 1; i >= 0; i-- {
+		if er := children[i].Close(); er != nil {
+			apocalypseErr.Errors = append(apocalypseErr.Errors, er.(*ApocalypseError).Errors...)
+		}
+	}
+	if er := gg.Apocalypse(); er != nil {
+		apocalypseErr.Errors = append(apocalypseErr.Errors, er.(*ApocalypseError).Errors...)
+	}
+
+	gg.mutex.Lock()
+	parent := gg.parent
+	gg.parent = nil
+	gg.mutex.Unlock()
+	if parent != nil {
+		parent.removeChild(gg)
+	}
+
+	if len(apocalypseErr.Errors) > 0 {
+		err = apocalypseErr
+	}
+
+	return
+}
+
+func (gg *GoGetter) removeChild(child *GoGetter) {
+	gg.mutex.Lock()
+	defer gg.mutex.Unlock()
+
+	for i, c := range gg.children {
+		if c == child {
+			gg.children = append(gg.children[:i:i], gg.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// Dreams returns dreams of the goal made by the GoGetter and its open scopes.
+func (gg *GoGetter) Dreams(name string) (dreams []Dream) {
+	gg.mutex.Lock()
+	dreams = append(dreams, gg.dreams[name]...)
+	children := append([]*GoGetter{}, gg.children...)
+	gg.mutex.Unlock()
+
+	for _, child := range children {
+		dreams = append(dreams, child.Dreams(name)...)
+	}
+
+	return
+}
+
+// collectGoals adds names of goals having dreams made by the GoGetter and its
+// open scopes to names.
+func (gg *GoGetter) collectGoals(names map[string]bool) {
+	gg.mutex.Lock()
+	for name, dreams := range gg.dreams {
+		if len(dreams) > 0 {
+			names[name] = true
+		}
+	}
+	children := append([]*GoGetter{}, gg.children...)
+	gg.mutex.Unlock()
+
+	for _, child := range children {
+		child.collectGoals(names)
+	}
+}
+
+// Adopt makes the GoGetter track dreams made by the scope and its open scopes,
+// which are not destroyed when the scope is closed any more.
+//
+// With CleanupByRollback, the transactions of the scopes are handed over too,
+// they are not rolled back by the scopes, but with the transaction of the
+// GoGetter, in which the savepoints of its own scopes are nested.
+func (gg *GoGetter) Adopt(scope *GoGetter) {
+	scope.mutex.Lock()
+	dreams, creations := scope.dreams, scope.creations
+	children := append([]*GoGetter{}, scope.children...)
+	scope.dreams = map[string][]Dream{}
+	scope.creations = map[string]int{}
+	scope.mutex.Unlock()
+
+	scope.txMutex.Lock()
+	tx := scope.tx
+	scope.tx = nil
+	scope.txMutex.Unlock()
+	if tx != nil && !gg.isAncestorOf(scope) {
+		gg.txMutex.Lock()
+		gg.adoptedTxs = append(gg.adoptedTxs, tx)
+		gg.txMutex.Unlock()
+	}
+
+	// Goals are adopted in the order of their creation in the scope.
+	names := make([]string, len(creations))
+	for name, i := range creations {
+		names[i] = name
+	}
+
+	gg.mutex.Lock()
+	for _, name := range names {
+		gg.dreams[name] = append(gg.dreams[name], dreams[name]...)
+		if _, ok := gg.creations[name]; !ok {
+			gg.creations[name] = len(gg.creations)
+		}
+	}
+	gg.mutex.Unlock()
+
+	for _, child := range children {
+		if child != gg {
+			gg.Adopt(child)
+		}
+	}
+}
+
+// isAncestorOf reports whether scope is created by the GoGetter or its scopes.
+func (gg *GoGetter) isAncestorOf(scope *GoGetter) bool {
+	for {
+		scope.mutex.Lock()
+		parent := scope.parent
+		scope.mutex.Unlock()
+		if parent == nil {
+			return false
+		}
+		if parent == gg {
+			return true
+		}
+		scope = parent
+	}
+}
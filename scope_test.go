@@ -0,0 +1,76 @@
+package gogetter
+
+import (
+	"errors"
+	. "launchpad.net/gocheck"
+)
+
+func (s *GoGetterSuite) TestScopeCleansOnlyItsOwn(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	gg.Realize("Member")
+	scope := gg.Scope()
+	scope.Realize("Member")
+	sub := scope.Scope()
+	sub.Realize("Account")
+
+	c.Check(gg.Dreams("Member"), HasLen, 2)
+	c.Check(scope.Dreams("Account"), HasLen, 1)
+	c.Check(sub.Dreams("Member"), HasLen, 0)
+
+	c.Check(sub.Close(), Equals, nil)
+	c.Check(db.removals, DeepEquals, []string{"accounts"})
+	c.Check(scope.Dreams("Account"), HasLen, 0)
+
+	c.Check(scope.Close(), Equals, nil)
+	c.Check(db.removals, DeepEquals, []string{"accounts", "members"})
+	c.Check(db.removed["members"], HasLen, 1)
+	c.Check(gg.Dreams("Member"), HasLen, 1)
+}
+
+func (s *GoGetterSuite) TestCloseNestedScopes(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	scope := gg.Scope()
+	scope.Realize("Member")
+	scope.Scope().Realize("Account")
+	scope.Scope().Realize("Fixture")
+
+	c.Check(gg.Close(), Equals, nil)
+	c.Check(db.removals, DeepEquals, []string{"fixtures", "accounts", "members"})
+	c.Check(gg.Dreams("Member"), HasLen, 0)
+}
+
+func (s *GoGetterSuite) TestScopeSharesSequences(c *C) {
+	gg := NewGoGetter(nil)
+	member, _ := gg.Grow("Member")
+	scoped, _ := gg.Scope().Grow("Member")
+	c.Check(scoped.(Member).Id, Equals, member.(Member).Id+1)
+}
+
+func (s *GoGetterSuite) TestAdoptScope(c *C) {
+	db := newFakeDb()
+	gg := NewGoGetter(db)
+	scope := gg.Scope()
+	scope.Realize("Member")
+	scope.Scope().Realize("Account")
+	gg.Adopt(scope)
+
+	c.Check(scope.Close(), Equals, nil)
+	c.Check(db.removals, HasLen, 0)
+	c.Check(gg.Close(), Equals, nil)
+	c.Check(db.removals, DeepEquals, []string{"accounts", "members"})
+}
+
+func (s *GoGetterSuite) TestCloseScopeError(c *C) {
+	db := newFakeDb()
+	db.failures["accounts"] = errors.New("locked")
+	gg := NewGoGetter(db)
+	gg.Scope().Realize("Account")
+	gg.Realize("Member")
+
+	err := gg.Close()
+	c.Assert(err, FitsTypeOf, &ApocalypseError{})
+	c.Check(err.(*ApocalypseError).Errors, HasLen, 1)
+	c.Check(db.removals, DeepEquals, []string{"members"})
+}
@@ -0,0 +1,187 @@
+package gogetter
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Sequence is a special Lesson value, it will be replaced by the next number of
+// the counter of the goal and field it is assigned to. Sequence itself is used as
+// a fmt format to generate string values, in which case an empty Sequence equals
+// to "%d". For integer fields, the number is used directly.
+//
+// Usage:
+//
+// 	gogetter.Grow("User", gogetter.Lesson{
+// 		"Email": gogetter.Sequence("user-%d@example.com"),
+// 	})
+//
+// Counters are kept on GoGetter, so different GoGetters never affect each other.
+type Sequence string
+
+// See (r *Registry) SetSequence.
+func SetSequence(name, field, format string) {
+	defaultRegistry.SetSequence(name, field, format)
+}
+
+// SetSequence makes every dream of the goal have a unique value in the field,
+// generated by Sequence(format). It works like a default Lesson, which means it
+// could still be overridden by Lessons of AscendGoal or Grow/Realize.
+//
+// Usage:
+//
+// 	gogetter.SetSequence("User", "Email", "user-%d@example.com")
+// 	gogetter.SetSequence("User", "Age", "")
+func (r *Registry) SetSequence(name, field, format string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.sequences[name] == nil {
+		r.sequences[name] = Lesson{}
+	}
+	r.sequences[name][field] = Sequence(format)
+}
+
+// See (r *Registry) ForkSequence.
+func ForkSequence(name string) {
+	defaultRegistry.ForkSequence(name)
+}
+
+// By default, goals created by AscendGoal share the counters of their parents,
+// for that they are stored in the same table, normally under the same unique
+// indexes. ForkSequence makes the goal keep its own counters.
+func (r *Registry) ForkSequence(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.forkedSequences[name] = true
+}
+
+// getSequenceOwner returns the name of the goal whose counters are used by name.
+func (r *Registry) getSequenceOwner(name string) string {
+	for !r.isSequenceForked(name) {
+		pg := r.getParentGoal(name)
+		if pg == nil {
+			break
+		}
+		name = pg.parent
+	}
+
+	return name
+}
+
+func (r *Registry) isSequenceForked(name string) bool {
+	for reg := r; reg != nil; reg = reg.parent {
+		reg.mutex.RLock()
+		forked, ok := reg.forkedSequences[name]
+		reg.mutex.RUnlock()
+		if ok {
+			return forked
+		}
+	}
+
+	return false
+}
+
+// getSequenceLesson merges the sequences set on the goal and all its parents,
+// the ones set on children take precedence.
+func (r *Registry) getSequenceLesson(name string) (lesson Lesson) {
+	names := r.getGoalChain(name)
+	regs := []*Registry{}
+	for reg := r; reg != nil; reg = reg.parent {
+		regs = append(regs, reg)
+	}
+
+	lesson = Lesson{}
+	for i := len(names) - 1; i >= 0; i-- {
+		for j := len(regs) - 1; j >= 0; j-- {
+			regs[j].mutex.RLock()
+			for field, seq := range regs[j].sequences[names[i]] {
+				lesson[field] = seq
+			}
+			regs[j].mutex.RUnlock()
+		}
+	}
+
+	return
+}
+
+type sequenceKey struct {
+	owner string
+	field string
+}
+
+type sequenceCounters struct {
+	values map[sequenceKey]int
+	mutex  sync.Mutex
+}
+
+func (s Sequence) set(field reflect.Value, n int) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(n))
+	case reflect.String:
+		format := string(s)
+		if format == "" {
+			format = "%d"
+		}
+		field.SetString(fmt.Sprintf(format, n))
+	default:
+		return &TypeMismatchError{Expected: field.Type(), Actual: reflect.TypeOf(s)}
+	}
+
+	return nil
+}
+
+// Next is (gg *GoGetter) Next of the default GoGetter, even in Goals called by
+// other GoGetters.
+func Next(name, field string) int {
+	return defaultGetter.Next(name, field)
+}
+
+// Next increases and returns the counter of the field of the goal, which starts
+// from 1. It could be used in Goals to generate unique values:
+//
+// 	gogetter.SetGoal("User", func() gogetter.Dream {
+// 		return User{Email: fmt.Sprintf("user-%d@example.com", gogetter.Next("User", "Email"))}
+// 	})
+//
+// Goals don't know the GoGetter calling them, use SetSequence instead to count
+// with the GoGetter making dreams.
+func (gg *GoGetter) Next(name, field string) int {
+	gg.sequences.mutex.Lock()
+	defer gg.sequences.mutex.Unlock()
+
+	key := sequenceKey{gg.registry.getSequenceOwner(name), field}
+	gg.sequences.values[key]++
+	return gg.sequences.values[key]
+}
+
+// See (gg *GoGetter) ResetSequences.
+func ResetSequences(names ...string) {
+	defaultGetter.ResetSequences(names...)
+}
+
+// ResetSequences resets counters of the goals to zero, or all the counters if
+// no name is provided. Shared counters are reset together with their owners.
+func (gg *GoGetter) ResetSequences(names ...string) {
+	gg.sequences.mutex.Lock()
+	defer gg.sequences.mutex.Unlock()
+
+	if len(names) == 0 {
+		gg.sequences.values = map[sequenceKey]int{}
+		return
+	}
+
+	for _, name := range names {
+		owner := gg.registry.getSequenceOwner(name)
+		for key, _ := range gg.sequences.values {
+			if key.owner == owner {
+				delete(gg.sequences.values, key)
+			}
+		}
+	}
+}
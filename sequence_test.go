@@ -0,0 +1,106 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+type Member struct {
+	Id    int
+	Email string
+	Name  string
+}
+
+func init() {
+	SetGoal("Member", func() Dream { return Member{Name: "member"} })
+	SetSequence("Member", "Email", "member-%d@example.com")
+	SetSequence("Member", "Id", "")
+
+	AscendGoal("Shared Member", "Member", func() Lesson { return Lesson{} })
+	AscendGoal("Forked Member", "Member", func() Lesson { return Lesson{} })
+	ForkSequence("Forked Member")
+}
+
+func (s *GoGetterSuite) TestSequence(c *C) {
+	gg := NewGoGetter(nil)
+	membersI, err := gg.Grow("Member", nil, nil)
+	c.Check(err, Equals, nil)
+	members := membersI.([]Member)
+	c.Check(members[0].Email, Not(Equals), members[1].Email)
+	c.Check(members[0].Id+members[1].Id, Equals, 3)
+
+	memberI, err := gg.Grow("Member", Lesson{"Name": Sequence("name %d")})
+	c.Check(err, Equals, nil)
+	c.Check(memberI.(Member).Email, Equals, "member-3@example.com")
+	c.Check(memberI.(Member).Name, Equals, "name 1")
+
+	memberI, err = gg.Grow("Member", Lesson{"Email": "custom@example.com"})
+	c.Check(err, Equals, nil)
+	c.Check(memberI.(Member).Email, Equals, "custom@example.com")
+	// Overridden sequences don't advance
+	memberI, err = gg.Grow("Member")
+	c.Check(err, Equals, nil)
+	c.Check(memberI.(Member).Email, Equals, "member-4@example.com")
+
+	memberI, err = NewGoGetter(nil).Grow("Member")
+	c.Check(err, Equals, nil)
+	c.Check(memberI.(Member).Email, Equals, "member-1@example.com")
+}
+
+func (s *GoGetterSuite) TestSequenceOfAscendGoals(c *C) {
+	gg := NewGoGetter(nil)
+	gg.Grow("Member")
+	memberI, err := gg.Grow("Shared Member")
+	c.Check(err, Equals, nil)
+	c.Check(memberI.(Member).Email, Equals, "member-2@example.com")
+
+	memberI, err = gg.Grow("Forked Member")
+	c.Check(err, Equals, nil)
+	c.Check(memberI.(Member).Email, Equals, "member-1@example.com")
+}
+
+func (s *GoGetterSuite) TestResetSequences(c *C) {
+	gg := NewGoGetter(nil)
+	c.Check(gg.Next("Member", "Email"), Equals, 1)
+	c.Check(gg.Next("Shared Member", "Email"), Equals, 2)
+	c.Check(gg.Next("Forked Member", "Email"), Equals, 1)
+
+	gg.ResetSequences("Shared Member")
+	c.Check(gg.Next("Member", "Email"), Equals, 1)
+	c.Check(gg.Next("Forked Member", "Email"), Equals, 2)
+
+	gg.ResetSequences()
+	c.Check(gg.Next("Forked Member", "Email"), Equals, 1)
+}
+
+func (s *GoGetterSuite) TestResetSequencesByOwner(c *C) {
+	gg := NewGoGetter(nil)
+	c.Check(gg.Next("Member", "Email"), Equals, 1)
+	c.Check(gg.Next("Member.Admin", "Email"), Equals, 1)
+	gg.ResetSequences("Member")
+	c.Check(gg.Next("Member", "Email"), Equals, 1)
+	c.Check(gg.Next("Member.Admin", "Email"), Equals, 2)
+}
+
+// Next in Goals counts with the default GoGetter, while Sequences count with
+// the GoGetter making dreams.
+func (s *GoGetterSuite) TestNextInGoals(c *C) {
+	ResetSequences("Numbered Member")
+	reg := NewRegistry(DefaultRegistry())
+	reg.SetGoal("Numbered Member", func() Dream {
+		return Member{Id: Next("Numbered Member", "Id")}
+	})
+	reg.SetSequence("Numbered Member", "Email", "member-%d")
+	gg := NewGoGetter(nil, reg)
+
+	membersI, err := gg.Grow("Numbered Member", nil, nil, nil)
+	c.Check(err, Equals, nil)
+	ids := []int{}
+	for _, member := range membersI.([]Member) {
+		ids = append(ids, member.Id)
+	}
+	c.Check(ids[0]+ids[1]+ids[2], Equals, 6)
+	c.Check(Next("Numbered Member", "Id"), Equals, 4)
+	c.Check(gg.Next("Numbered Member", "Id"), Equals, 1)
+	c.Check(gg.Next("Numbered Member", "Email"), Equals, 4)
+	c.Check(defaultGetter.Next("Numbered Member", "Email"), Equals, 1)
+}
@@ -0,0 +1,475 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/bom-d-van/gogetter"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var ErrNotInTransaction = errors.New("sqldriver: not in a transaction")
+
+// MaxPlaceholders limits the placeholders of a statement, records or ids
+// beyond it are inserted or removed in more statements. It's a conservative
+// default accepted by all the dialects, including old versions of SQLite.
+var MaxPlaceholders = 999
+
+// SqlDb is a gogetter Database on top of database/sql. Fields of records are
+// mapped to columns by their db tags:
+//
+// 	type User struct {
+// 		Id        int64     `db:"id,auto"`
+// 		Name      string    `db:"name"`
+// 		CreatedAt time.Time // created_at
+// 		Cache     string    `db:"-"` // Skipped
+// 	}
+//
+// Fields without tags are mapped to the snake case of their names, embedded
+// structs without tags are flattened. Columns with the auto option, e.g.
+// auto-increment ids, are left out of INSERT for the records they are zero in,
+// which are inserted apart from the ones setting them.
+//
+// SqlDb is Transactional, so it could be used with gogetter.CleanupByRollback,
+// nested transactions are savepoints. It's also a Truncater, and a Storer
+// writing generated auto columns back to dreams.
+type SqlDb struct {
+	db        *sql.DB
+	tx        *sql.Tx
+	savepoint string
+	dialect   Dialect
+	shared    *shared
+}
+
+// shared is shared by a SqlDb and all its transactions.
+type shared struct {
+	mutex      sync.Mutex
+	types      map[string]reflect.Type
+	savepoints int
+}
+
+var _ gogetter.Transaction = &SqlDb{}
+var _ gogetter.Truncater = &SqlDb{}
+var _ gogetter.Storer = &SqlDb{}
+
+func NewSqlDb(db *sql.DB, dialect Dialect) *SqlDb {
+	return &SqlDb{db: db, dialect: dialect, shared: &shared{types: map[string]reflect.Type{}}}
+}
+
+// DB returns the underlying *sql.DB.
+func (s *SqlDb) DB() *sql.DB {
+	return s.db
+}
+
+// Tx returns the transaction of the SqlDb, or nil if it's not in a transaction.
+func (s *SqlDb) Tx() *sql.Tx {
+	return s.tx
+}
+
+func (s *SqlDb) Create(table string, records ...interface{}) (err error) {
+	values, err := s.values(table, records)
+	if err != nil {
+		return
+	}
+
+	for _, group := range groupByAutos(values) {
+		if err = s.insertAll(table, group); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// insertAll inserts values with the same zero auto columns, in as few
+// statements as MaxPlaceholders allows.
+func (s *SqlDb) insertAll(table string, values []reflect.Value) (err error) {
+	columns, _ := splitColumns(values)
+	rows := 1
+	if len(columns) > 0 && MaxPlaceholders > len(columns) {
+		rows = MaxPlaceholders / len(columns)
+	}
+	for len(values) > 0 {
+		n := rows
+		if n > len(values) {
+			n = len(values)
+		}
+		query, args := s.insert(table, columns, values[:n])
+		if _, err = s.exec(query, args...); err != nil {
+			return
+		}
+		values = values[n:]
+	}
+
+	return
+}
+
+// Store implements gogetter.Storer. Unlike Create, records of pointers with
+// zero auto columns are inserted one by one, and the generated values are
+// written back to them, with RETURNING of the dialect, or LastInsertId if the
+// dialect has none, in which case only the first integer auto column is set.
+func (s *SqlDb) Store(table string, records ...interface{}) (err error) {
+	values, err := s.values(table, records)
+	if err != nil {
+		return
+	}
+
+	for _, v := range values {
+		columns, autos := splitColumns([]reflect.Value{v})
+		query, args := s.insert(table, columns, []reflect.Value{v})
+		if len(autos) == 0 || !v.CanAddr() {
+			if _, err = s.exec(query, args...); err != nil {
+				return
+			}
+			continue
+		}
+
+		names := []string{}
+		dests := []interface{}{}
+		for _, col := range autos {
+			names = append(names, s.dialect.Quote(col.name))
+			dests = append(dests, v.FieldByIndex(col.index).Addr().Interface())
+		}
+		if returning := s.dialect.Returning(names); returning != "" {
+			if err = s.queryRow(query+" "+returning, args...).Scan(dests...); err != nil {
+				return
+			}
+			continue
+		}
+
+		var result sql.Result
+		if result, err = s.exec(query, args...); err != nil {
+			return
+		}
+		var id int64
+		if id, err = result.LastInsertId(); err != nil {
+			return
+		}
+		switch f := v.FieldByIndex(autos[0].index); f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.SetInt(id)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.SetUint(uint64(id))
+		}
+	}
+
+	return
+}
+
+// values validates records, which must be structs, or pointers to them, of the
+// same type.
+func (s *SqlDb) values(table string, records []interface{}) (values []reflect.Value, err error) {
+	for _, record := range records {
+		v := reflect.ValueOf(record)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct || (len(values) > 0 && v.Type() != values[0].Type()) {
+			return nil, fmt.Errorf("sqldriver: can't create %T in %s", record, table)
+		}
+		values = append(values, v)
+	}
+	if len(values) > 0 {
+		s.shared.setType(table, values[0].Type())
+	}
+
+	return
+}
+
+// insert returns the statement inserting columns of values in one go. Without
+// any columns, it inserts a row of default values, values must be one.
+func (s *SqlDb) insert(table string, columns []column, values []reflect.Value) (query string, args []interface{}) {
+	if len(columns) == 0 {
+		return fmt.Sprintf("INSERT INTO %s %s", s.dialect.Quote(table), s.dialect.DefaultValues()), nil
+	}
+
+	names := []string{}
+	for _, col := range columns {
+		names = append(names, s.dialect.Quote(col.name))
+	}
+	rows := []string{}
+	for _, v := range values {
+		placeholders := []string{}
+		for _, col := range columns {
+			args = append(args, v.FieldByIndex(col.index).Interface())
+			placeholders = append(placeholders, s.dialect.Placeholder(len(args)))
+		}
+		rows = append(rows, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	query = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", s.dialect.Quote(table), strings.Join(names, ", "), strings.Join(rows, ", "))
+	return
+}
+
+// Remove deletes the records by ids. idField is the Go field name, which is
+// mapped to its column by the type of records created in the table, or its
+// snake case if nothing is created in the table by the SqlDb.
+func (s *SqlDb) Remove(table string, idField string, ids ...interface{}) (err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	column := s.dialect.Quote(s.columnName(table, idField))
+	for len(ids) > 0 {
+		n := len(ids)
+		if MaxPlaceholders > 0 && n > MaxPlaceholders {
+			n = MaxPlaceholders
+		}
+		placeholders := []string{}
+		for i := 0; i < n; i++ {
+			placeholders = append(placeholders, s.dialect.Placeholder(i+1))
+		}
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", s.dialect.Quote(table), column, strings.Join(placeholders, ", "))
+		if _, err = s.exec(query, ids[:n]...); err != nil {
+			return
+		}
+		ids = ids[n:]
+	}
+
+	return
+}
+
+// Truncate removes all rows of the tables with the statements of the dialect.
+// Its cleanup statements are executed even if truncation fails, and if any of
+// them fails, the connection is discarded instead of being put back in the pool.
+func (s *SqlDb) Truncate(tables ...string) (err error) {
+	stmts, cleanups := s.dialect.Truncate(tables)
+	exec, discard := s.exec, func() {}
+	if s.tx == nil {
+		// Statements like SET FOREIGN_KEY_CHECKS only work on the same connection.
+		var conn *sql.Conn
+		if conn, err = s.db.Conn(context.Background()); err != nil {
+			return
+		}
+		defer conn.Close()
+		exec = func(query string, args ...interface{}) (sql.Result, error) {
+			return conn.ExecContext(context.Background(), query, args...)
+		}
+		discard = func() {
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}
+	defer func() {
+		for _, stmt := range cleanups {
+			if _, er := exec(stmt); er != nil {
+				discard()
+				if err == nil {
+					err = er
+				} else {
+					err = fmt.Errorf("%v, and %s failed: %v", err, stmt, er)
+				}
+			}
+		}
+	}()
+
+	for _, stmt := range stmts {
+		if _, err = exec(stmt); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Begin begins a transaction, or a savepoint if the SqlDb is in a transaction.
+// Transactions must not be used concurrently.
+func (s *SqlDb) Begin() (gogetter.Transaction, error) {
+	if s.tx == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return nil, err
+		}
+		return &SqlDb{db: s.db, tx: tx, dialect: s.dialect, shared: s.shared}, nil
+	}
+
+	s.shared.mutex.Lock()
+	s.shared.savepoints++
+	savepoint := fmt.Sprintf("gogetter_%d", s.shared.savepoints)
+	s.shared.mutex.Unlock()
+	if _, err := s.tx.Exec("SAVEPOINT " + savepoint); err != nil {
+		return nil, err
+	}
+
+	return &SqlDb{db: s.db, tx: s.tx, savepoint: savepoint, dialect: s.dialect, shared: s.shared}, nil
+}
+
+// Rollback rolls back the transaction, or to the savepoint.
+func (s *SqlDb) Rollback() (err error) {
+	if s.tx == nil {
+		return ErrNotInTransaction
+	}
+	if s.savepoint == "" {
+		return s.tx.Rollback()
+	}
+
+	_, err = s.tx.Exec("ROLLBACK TO SAVEPOINT " + s.savepoint)
+	return
+}
+
+// Commit commits the transaction, or releases the savepoint.
+func (s *SqlDb) Commit() (err error) {
+	if s.tx == nil {
+		return ErrNotInTransaction
+	}
+	if s.savepoint == "" {
+		return s.tx.Commit()
+	}
+
+	_, err = s.tx.Exec("RELEASE SAVEPOINT " + s.savepoint)
+	return
+}
+
+func (s *SqlDb) exec(query string, args ...interface{}) (sql.Result, error) {
+	if s.tx != nil {
+		return s.tx.Exec(query, args...)
+	}
+
+	return s.db.Exec(query, args...)
+}
+
+func (s *SqlDb) queryRow(query string, args ...interface{}) *sql.Row {
+	if s.tx != nil {
+		return s.tx.QueryRow(query, args...)
+	}
+
+	return s.db.QueryRow(query, args...)
+}
+
+func (s *SqlDb) columnName(table, field string) string {
+	if t := s.shared.getType(table); t != nil {
+		for _, col := range columnsOf(t) {
+			if col.field == field {
+				return col.name
+			}
+		}
+	}
+
+	return snakeCase(field)
+}
+
+func (sh *shared) setType(table string, t reflect.Type) {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	sh.types[table] = t
+}
+
+func (sh *shared) getType(table string) reflect.Type {
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	return sh.types[table]
+}
+
+type column struct {
+	name  string
+	field string
+	index []int
+	auto  bool
+}
+
+// columnsOf returns the columns of struct type t, see SqlDb.
+func columnsOf(t reflect.Type) (columns []column) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("db")
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		if options[0] == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for _, col := range columnsOf(field.Type) {
+				col.index = append([]int{i}, col.index...)
+				columns = append(columns, col)
+			}
+			continue
+		}
+
+		col := column{name: options[0], field: field.Name, index: []int{i}}
+		if col.name == "" {
+			col.name = snakeCase(field.Name)
+		}
+		for _, option := range options[1:] {
+			if option == "auto" {
+				col.auto = true
+			}
+		}
+		columns = append(columns, col)
+	}
+
+	return
+}
+
+// groupByAutos groups values by their zero auto columns, in the order of the
+// first values of the groups, so that every group is inserted with its own
+// columns, instead of zeros of the auto columns set in other values.
+func groupByAutos(values []reflect.Value) (groups [][]reflect.Value) {
+	indexes := map[string]int{}
+	for _, v := range values {
+		autos := []string{}
+		for _, col := range columnsOf(v.Type()) {
+			if col.auto && isZeroColumn([]reflect.Value{v}, col) {
+				autos = append(autos, col.name)
+			}
+		}
+		key := strings.Join(autos, ",")
+		i, ok := indexes[key]
+		if !ok {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], v)
+	}
+
+	return
+}
+
+// splitColumns splits the columns of values into the ones to insert and the
+// auto ones left out, which are zero in all values.
+func splitColumns(values []reflect.Value) (columns, autos []column) {
+	for _, col := range columnsOf(values[0].Type()) {
+		if col.auto && isZeroColumn(values, col) {
+			autos = append(autos, col)
+		} else {
+			columns = append(columns, col)
+		}
+	}
+
+	return
+}
+
+func isZeroColumn(values []reflect.Value, col column) bool {
+	for _, v := range values {
+		f := v.FieldByIndex(col.index)
+		if !reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// snakeCase converts names like "AuthorId" and "HTMLBody" into "author_id"
+// and "html_body".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	out := []rune{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+
+	return string(out)
+}
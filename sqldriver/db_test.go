@@ -0,0 +1,259 @@
+package sqldriver
+
+import (
+	"database/sql"
+	"github.com/bom-d-van/gogetter"
+	"github.com/bom-d-van/gogetter/drivertest"
+	. "launchpad.net/gocheck"
+	_ "modernc.org/sqlite"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type SqlDbSuite struct{ *SqlDb }
+
+var _ = Suite(&SqlDbSuite{})
+
+var _ = Suite(&drivertest.Suite{New: func(c *C) *drivertest.Fixture {
+	db, err := sql.Open("sqlite", filepath.Join(c.MkDir(), "gogetter.db"))
+	c.Assert(err, Equals, nil)
+	_, err = db.Exec(`CREATE TABLE drivertest_records (id INTEGER PRIMARY KEY, code TEXT, name TEXT)`)
+	c.Assert(err, Equals, nil)
+	return &drivertest.Fixture{
+		Db: NewSqlDb(db, SQLite),
+		Count: func(table string) (n int, err error) {
+			err = db.QueryRow("SELECT COUNT(*) FROM " + SQLite.Quote(table)).Scan(&n)
+			return
+		},
+		Close: func() { db.Close() },
+	}
+}})
+
+type Timestamps struct {
+	CreatedAt time.Time
+}
+
+type User struct {
+	Id    int64  `db:"id,auto"`
+	Name  string `db:"full_name"`
+	Cache string `db:"-"`
+	Timestamps
+}
+
+type Visit struct {
+	Id int64 `db:",auto"`
+}
+
+type Post struct {
+	Id       int64 `db:",auto"`
+	AuthorId int64
+}
+
+func (s *SqlDbSuite) SetUpTest(c *C) {
+	db, err := sql.Open("sqlite", filepath.Join(c.MkDir(), "gogetter.db"))
+	c.Assert(err, Equals, nil)
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, full_name TEXT, created_at DATETIME)`)
+	c.Assert(err, Equals, nil)
+	_, err = db.Exec(`CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, author_id INTEGER REFERENCES users(id))`)
+	c.Assert(err, Equals, nil)
+	_, err = db.Exec(`CREATE TABLE visits (id INTEGER PRIMARY KEY AUTOINCREMENT)`)
+	c.Assert(err, Equals, nil)
+	s.SqlDb = NewSqlDb(db, SQLite)
+}
+
+func (s *SqlDbSuite) TearDownTest(c *C) {
+	s.db.Close()
+}
+
+func (s *SqlDbSuite) count(c *C, query string, args ...interface{}) (n int) {
+	err := s.db.QueryRow(query, args...).Scan(&n)
+	c.Assert(err, Equals, nil)
+	return
+}
+
+func (s *SqlDbSuite) TestCreateAndRemove(c *C) {
+	err := s.Create("users", User{Name: "Van", Cache: "x"}, &User{Name: "Gogh"})
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users WHERE full_name IN ('Van', 'Gogh') AND id > 0`), Equals, 2)
+
+	err = s.Create("users", User{Id: 10, Name: "Ten", Timestamps: Timestamps{CreatedAt: time.Now()}})
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users WHERE id = 10 AND created_at IS NOT NULL`), Equals, 1)
+
+	err = s.Remove("users", "Id", int64(1), int64(10))
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 1)
+
+	// Columns are guessed for tables unknown to the SqlDb
+	err = NewSqlDb(s.db, SQLite).Remove("users", "Id", int64(2))
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 0)
+}
+
+// Records setting auto columns are inserted apart from the ones leaving them to
+// the database.
+func (s *SqlDbSuite) TestCreateMixedIds(c *C) {
+	err := s.Create("users", User{Name: "Van"}, User{Id: 10, Name: "Ten"}, &User{Name: "Gogh"})
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users WHERE id = 10 AND full_name = 'Ten'`), Equals, 1)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users WHERE id IN (1, 2) AND full_name IN ('Van', 'Gogh')`), Equals, 2)
+
+	reg := gogetter.NewRegistry(nil)
+	reg.SetGoal("User", func() gogetter.Dream { return User{Name: "Vincent"} })
+	gg := gogetter.NewGoGetter(s.SqlDb, reg)
+	_, err = gg.Realize("User", nil, gogetter.Lesson{"Id": int64(20)}, nil)
+	c.Check(err, Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users WHERE full_name = 'Vincent' AND id > 0`), Equals, 3)
+}
+
+func (s *SqlDbSuite) TestCreateDefaultValues(c *C) {
+	c.Check(s.Create("visits", Visit{}, &Visit{}), Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM visits`), Equals, 2)
+
+	visit := &Visit{}
+	c.Check(s.Store("visits", visit), Equals, nil)
+	c.Check(visit.Id, Equals, int64(3))
+}
+
+func (s *SqlDbSuite) TestCreateInBatches(c *C) {
+	defer func(max int) { MaxPlaceholders = max }(MaxPlaceholders)
+	MaxPlaceholders = 5
+
+	users := []interface{}{}
+	for i := 0; i < 5; i++ {
+		users = append(users, User{Name: "Van"})
+	}
+	c.Check(s.Create("users", users...), Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 5)
+
+	MaxPlaceholders = 2
+	c.Check(s.Remove("users", "Id", int64(1), int64(2), int64(3), int64(5), int64(6)), Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users WHERE id = 4`), Equals, 1)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 1)
+}
+
+func (s *SqlDbSuite) TestCreateErrors(c *C) {
+	c.Check(s.Create("users", 1), ErrorMatches, "sqldriver: can't create int in users")
+	c.Check(s.Create("users", User{}, Post{}), ErrorMatches, `sqldriver: can't create sqldriver.Post in users`)
+	c.Check(s.Create("nothings", User{}), Not(Equals), nil)
+}
+
+func (s *SqlDbSuite) TestStore(c *C) {
+	user := &User{Name: "Van"}
+	post := &Post{}
+	err := s.Store("users", user, &User{Id: 10, Name: "Ten"}, User{Name: "Gogh"})
+	c.Check(err, Equals, nil)
+	c.Check(user.Id, Equals, int64(1))
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users WHERE id IN (1, 10, 11)`), Equals, 3)
+
+	tx, err := s.Begin()
+	c.Assert(err, Equals, nil)
+	c.Check(tx.(*SqlDb).Store("posts", post), Equals, nil)
+	c.Check(post.Id, Equals, int64(1))
+	c.Check(tx.Rollback(), Equals, nil)
+}
+
+func (s *SqlDbSuite) TestTransactions(c *C) {
+	tx, err := s.Begin()
+	c.Assert(err, Equals, nil)
+	c.Check(tx.Create("users", User{Name: "Van"}), Equals, nil)
+
+	savepoint, err := tx.Begin()
+	c.Assert(err, Equals, nil)
+	c.Check(savepoint.Create("users", User{Name: "Gogh"}), Equals, nil)
+	c.Check(savepoint.Rollback(), Equals, nil)
+
+	var names []string
+	rows, err := tx.(*SqlDb).Tx().Query(`SELECT full_name FROM users`)
+	c.Assert(err, Equals, nil)
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		names = append(names, name)
+	}
+	rows.Close()
+	c.Check(names, DeepEquals, []string{"Van"})
+
+	c.Check(tx.Rollback(), Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 0)
+	c.Check(s.Rollback(), Equals, ErrNotInTransaction)
+}
+
+func (s *SqlDbSuite) TestTruncate(c *C) {
+	s.Create("users", User{Id: 1}, User{Id: 2})
+	s.Create("posts", Post{AuthorId: 1})
+	c.Check(s.Truncate("posts", "users"), Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 0)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM posts`), Equals, 0)
+}
+
+// cleanupDialect is SQLite with cleanup statements of Truncate.
+type cleanupDialect struct {
+	Dialect
+	cleanups []string
+}
+
+func (d cleanupDialect) Truncate(tables []string) (stmts, cleanups []string) {
+	stmts, _ = d.Dialect.Truncate(tables)
+	return stmts, d.cleanups
+}
+
+func (s *SqlDbSuite) TestTruncateCleanups(c *C) {
+	db := NewSqlDb(s.db, cleanupDialect{SQLite, []string{`INSERT INTO visits DEFAULT VALUES`}})
+	c.Check(db.Truncate("nothings"), ErrorMatches, ".*no such table: nothings.*")
+	c.Check(s.count(c, `SELECT COUNT(*) FROM visits`), Equals, 1)
+
+	db = NewSqlDb(s.db, cleanupDialect{SQLite, []string{`INSERT INTO nothings DEFAULT VALUES`}})
+	c.Check(db.Truncate("visits"), ErrorMatches, ".*no such table: nothings.*")
+	c.Check(s.count(c, `SELECT COUNT(*) FROM visits`), Equals, 0)
+	c.Check(db.Truncate("nothings"), ErrorMatches, ".*no such table: nothings.*, and INSERT INTO nothings DEFAULT VALUES failed: .*")
+
+	tx, err := db.Begin()
+	c.Assert(err, Equals, nil)
+	c.Check(tx.(*SqlDb).Truncate("visits"), ErrorMatches, ".*no such table: nothings.*")
+	c.Check(tx.Rollback(), Equals, nil)
+}
+
+func (s *SqlDbSuite) TestWithGoGetter(c *C) {
+	reg := gogetter.NewRegistry(nil)
+	reg.SetGoal("User", func() gogetter.Dream { return User{Name: "Van"} })
+	gg := gogetter.NewGoGetter(s.SqlDb, reg)
+
+	usersI, err := gg.Realize("User", nil, nil)
+	c.Check(err, Equals, nil)
+	c.Check(usersI.([]User)[1].Id, Equals, int64(2))
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 2)
+	c.Check(gg.Apocalypse(), Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 0)
+
+	gg.SetCleanupStrategy(gogetter.CleanupByRollback)
+	_, err = gg.Realize("User")
+	c.Check(err, Equals, nil)
+	c.Check(gg.Close(), Equals, nil)
+	c.Check(s.count(c, `SELECT COUNT(*) FROM users`), Equals, 0)
+}
+
+func (s *SqlDbSuite) TestDialects(c *C) {
+	c.Check(MySQL.Placeholder(2), Equals, "?")
+	c.Check(Postgres.Placeholder(2), Equals, "$2")
+	c.Check(MySQL.Quote("us`ers"), Equals, "`us``ers`")
+	c.Check(Postgres.Quote(`us"ers`), Equals, `"us""ers"`)
+	stmts, cleanups := MySQL.Truncate([]string{"posts", "users"})
+	c.Check(stmts, DeepEquals, []string{"SET FOREIGN_KEY_CHECKS = 0", "TRUNCATE TABLE `posts`", "TRUNCATE TABLE `users`"})
+	c.Check(cleanups, DeepEquals, []string{"SET FOREIGN_KEY_CHECKS = 1"})
+	stmts, cleanups = Postgres.Truncate([]string{"posts", "users"})
+	c.Check(stmts, DeepEquals, []string{`TRUNCATE TABLE "posts", "users"`})
+	c.Check(cleanups, IsNil)
+	stmts, _ = SQLite.Truncate([]string{"posts"})
+	c.Check(stmts, DeepEquals, []string{`DELETE FROM "posts"`})
+	c.Check(MySQL.Returning([]string{"`id`"}), Equals, "")
+	c.Check(MySQL.DefaultValues(), Equals, "() VALUES ()")
+	c.Check(Postgres.DefaultValues(), Equals, "DEFAULT VALUES")
+	c.Check(Postgres.Returning([]string{`"id"`, `"created_at"`}), Equals, `RETURNING "id", "created_at"`)
+	c.Check(snakeCase("HTMLBody"), Equals, "html_body")
+	c.Check(snakeCase("AuthorId"), Equals, "author_id")
+}
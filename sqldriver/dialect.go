@@ -0,0 +1,99 @@
+package sqldriver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect generates the database specific parts of SQL statements.
+type Dialect interface {
+	// Placeholder returns the placeholder of the nth (starting from 1) argument.
+	Placeholder(n int) string
+	// Quote quotes an identifier, e.g. a table or column name.
+	Quote(name string) string
+	// Truncate returns the statements removing all rows of the tables, which
+	// are executed in order on the same connection, and the cleanup ones,
+	// e.g. restoring session settings, which are executed after them even if
+	// they fail. Tables are in the order that dependents come before their
+	// dependencies.
+	Truncate(tables []string) (stmts, cleanups []string)
+	// Returning returns the clause appended to INSERT for returning the
+	// quoted columns, or "" if it's not supported, in which case
+	// LastInsertId is used.
+	Returning(columns []string) string
+	// DefaultValues returns the clause of INSERT for a row of default values.
+	DefaultValues() string
+}
+
+var (
+	MySQL    Dialect = mysql{}
+	Postgres Dialect = postgres{}
+	SQLite   Dialect = sqlite{}
+)
+
+type mysql struct{}
+
+func (mysql) Placeholder(n int) string { return "?" }
+
+func (mysql) Quote(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// Truncate disables foreign key checks, for that MySQL refuses to truncate
+// tables referenced by foreign keys, even if the referencing tables are empty.
+// Note that TRUNCATE causes an implicit commit in MySQL.
+func (d mysql) Truncate(tables []string) (stmts, cleanups []string) {
+	stmts = append(stmts, "SET FOREIGN_KEY_CHECKS = 0")
+	for _, table := range tables {
+		stmts = append(stmts, "TRUNCATE TABLE "+d.Quote(table))
+	}
+	return stmts, []string{"SET FOREIGN_KEY_CHECKS = 1"}
+}
+
+func (mysql) Returning(columns []string) string { return "" }
+
+func (mysql) DefaultValues() string { return "() VALUES ()" }
+
+type postgres struct{}
+
+func (postgres) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+func (postgres) Quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// Truncate truncates all the tables in one statement, which is allowed with
+// foreign keys between them.
+func (d postgres) Truncate(tables []string) (stmts, cleanups []string) {
+	quoted := []string{}
+	for _, table := range tables {
+		quoted = append(quoted, d.Quote(table))
+	}
+	return []string{"TRUNCATE TABLE " + strings.Join(quoted, ", ")}, nil
+}
+
+func (postgres) Returning(columns []string) string {
+	return "RETURNING " + strings.Join(columns, ", ")
+}
+
+func (postgres) DefaultValues() string { return "DEFAULT VALUES" }
+
+type sqlite struct{}
+
+func (sqlite) Placeholder(n int) string { return "?" }
+
+func (sqlite) Quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// Truncate deletes rows of the tables in order, as SQLite has no TRUNCATE.
+func (d sqlite) Truncate(tables []string) (stmts, cleanups []string) {
+	for _, table := range tables {
+		stmts = append(stmts, "DELETE FROM "+d.Quote(table))
+	}
+	return
+}
+
+func (sqlite) Returning(columns []string) string { return "" }
+
+func (sqlite) DefaultValues() string { return "DEFAULT VALUES" }
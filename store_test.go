@@ -0,0 +1,52 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+	"reflect"
+)
+
+type Ticket struct {
+	Id    int
+	Title string
+}
+
+// fakeStoreDb is a fakeDb supporting Store, which assigns ids to records
+// without an id.
+type fakeStoreDb struct {
+	*fakeDb
+	stored int
+}
+
+func (db *fakeStoreDb) Store(table string, records ...interface{}) error {
+	for _, record := range records {
+		db.stored++
+		if id := reflect.ValueOf(record).Elem().FieldByName("Id"); id.IsValid() && id.Int() == 0 {
+			id.SetInt(int64(db.stored))
+		}
+	}
+	return db.Create(table, records...)
+}
+
+func (s *GoGetterSuite) TestStore(c *C) {
+	reg := NewRegistry(DefaultRegistry())
+	reg.SetGoal("Ticket", func() Dream { return Ticket{Title: "Stored"} })
+	db := &fakeStoreDb{fakeDb: newFakeDb()}
+	gg := NewGoGetter(db, reg)
+
+	ticketsI, err := gg.Realize("Ticket", nil, Lesson{"Id": 10})
+	c.Check(err, Equals, nil)
+	c.Check(ticketsI, DeepEquals, []Ticket{{1, "Stored"}, {10, "Stored"}})
+	ticketI, err := gg.Realize("*Ticket")
+	c.Check(err, Equals, nil)
+	c.Check(ticketI, DeepEquals, &Ticket{3, "Stored"})
+
+	// Hooks see the stored dreams, and they are written back to the same value
+	articlesI, err := gg.Realize("Article", nil, nil)
+	c.Check(err, Equals, nil)
+	articles := articlesI.([]Article)
+	c.Check(articles[1].Serial, Equals, 2)
+	c.Check(articles[1].Created, Equals, true)
+
+	c.Check(gg.Apocalypse("Ticket"), Equals, nil)
+	c.Check(db.removed["tickets"], DeepEquals, []interface{}{1, 10, 3})
+}
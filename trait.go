@@ -0,0 +1,79 @@
+package gogetter
+
+import (
+	"strings"
+)
+
+// See (r *Registry) SetTrait.
+func SetTrait(name, trait string, lesson func() Lesson) {
+	defaultRegistry.SetTrait(name, trait, lesson)
+}
+
+// SetTrait registers a named Lesson for the goal, which could be requested
+// in any combination at Grow/Realize time, with the trait names separated by
+// commas after a colon (:) in the goal name. Traits are applied in the
+// requested order, after the Lessons of AscendGoal and before the ones passed
+// to Grow/Realize. Goals created by AscendGoal inherit traits of their parents.
+//
+// Usage:
+//
+// 	gogetter.SetTrait("User", "admin", func() gogetter.Lesson {
+// 		return gogetter.Lesson{"Role": "admin"}
+// 	})
+// 	gogetter.SetTrait("User", "suspended", func() gogetter.Lesson {
+// 		return gogetter.Lesson{"Suspended": true}
+// 	})
+//
+// 	gogetter.Grow("*User:admin,suspended", gogetter.Lesson{"Name": "Van"})
+func (r *Registry) SetTrait(name, trait string, lesson func() Lesson) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.traits[name] == nil {
+		r.traits[name] = map[string]func() Lesson{}
+	}
+	r.traits[name][trait] = lesson
+}
+
+// getTrait finds the trait in the goal and its parents.
+func (r *Registry) getTrait(name, trait string) func() Lesson {
+	for _, goal := range r.getGoalChain(name) {
+		for reg := r; reg != nil; reg = reg.parent {
+			reg.mutex.RLock()
+			lesson, ok := reg.traits[goal][trait]
+			reg.mutex.RUnlock()
+			if ok {
+				return lesson
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseTraits splits "User:admin,suspended" into the goal name and its traits,
+// names of goals that are set are returned as they are.
+func (r *Registry) parseTraits(name string) (goal string, traits []string) {
+	i := strings.LastIndex(name, ":")
+	if i < 0 || r.GetGoal(name) != nil {
+		return name, nil
+	}
+
+	for _, trait := range strings.Split(name[i+1:], ",") {
+		if trait = strings.TrimSpace(trait); trait != "" {
+			traits = append(traits, trait)
+		}
+	}
+
+	return name[:i], traits
+}
+
+// getTraitLessons returns lessons of the traits, the latter ones come first as
+// they take precedence.
+func (r *Registry) getTraitLessons(name string, traits []string) (lessons []Lesson) {
+	for i := len(traits) - 1; i >= 0; i-- {
+		lessons = append(lessons, r.getTrait(name, traits[i])())
+	}
+
+	return
+}
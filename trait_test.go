@@ -0,0 +1,48 @@
+package gogetter
+
+import (
+	. "launchpad.net/gocheck"
+)
+
+func init() {
+	SetTrait("User", "dreamer", func() Lesson {
+		return Lesson{"Name": "Dreamer", "Dream.Title": "Dreamer's Dream"}
+	})
+	SetTrait("User", "traveler", func() Lesson {
+		return Lesson{"Name": "Traveler", "VisitedPlaces": []string{"Everywhere"}}
+	})
+	SetTrait("Super User", "idealist", func() Lesson {
+		return Lesson{"ThereGreatIdeas[0]": "Change the world"}
+	})
+}
+
+func (s *GoGetterSuite) TestTraits(c *C) {
+	userI, err := Grow("*User:dreamer,traveler")
+	c.Check(err, Equals, nil)
+	user := userI.(*User)
+	c.Check(user.Name, Equals, "Traveler")
+	c.Check(user.Dream.Title, Equals, "Dreamer's Dream")
+	c.Check(user.VisitedPlaces, DeepEquals, []string{"Everywhere"})
+
+	userI, err = Grow("User:traveler, dreamer", Lesson{"Name": "Van"})
+	c.Check(err, Equals, nil)
+	c.Check(userI.(User).Name, Equals, "Van")
+
+	gg := NewGoGetter(nil)
+	gg.Grow("User:dreamer", nil, nil)
+	c.Check(gg.dreams["User"], HasLen, 2)
+
+	_, err = Grow("User:nightmare")
+	c.Check(err, Not(Equals), nil)
+}
+
+func (s *GoGetterSuite) TestTraitsOfAscendGoals(c *C) {
+	userI, err := Grow("Super User:dreamer,idealist")
+	c.Check(err, Equals, nil)
+	user := userI.(User)
+	c.Check(user.Name, Equals, "Dreamer")
+	c.Check(user.ThereGreatIdeas[0], Equals, "Change the world")
+
+	_, err = Grow("User:idealist")
+	c.Check(err, Not(Equals), nil)
+}
@@ -0,0 +1,103 @@
+package gogetter
+
+import (
+	"errors"
+)
+
+// Transactional is implemented by Databases supporting transactions, which are
+// required by CleanupByRollback.
+type Transactional interface {
+	Database
+	Begin() (Transaction, error)
+}
+
+// Transaction is a Database in a transaction. Begin of a Transaction begins a
+// nested transaction, e.g. a savepoint in SQL databases, which is used by
+// scopes of a GoGetter rolling back its transaction.
+type Transaction interface {
+	Transactional
+	Rollback() error
+}
+
+var ErrNotTransactional = errors.New("gogetter: database doesn't support transactions")
+
+// Db returns the Database used by the GoGetter to create records, which is the
+// transaction with CleanupByRollback. The transaction is begun if it's not yet.
+func (gg *GoGetter) Db() (db Database, err error) {
+	if gg.getOptions().cleanupStrategy != CleanupByRollback {
+		return gg.getDb(), nil
+	}
+
+	gg.txMutex.Lock()
+	defer gg.txMutex.Unlock()
+
+	if gg.tx != nil {
+		return gg.tx, nil
+	}
+
+	db = gg.getDb()
+	gg.mutex.Lock()
+	parent := gg.parent
+	gg.mutex.Unlock()
+	if parent != nil {
+		if db, err = parent.Db(); err != nil {
+			return
+		}
+	}
+	if db == nil {
+		return
+	}
+
+	t, ok := db.(Transactional)
+	if !ok {
+		return nil, ErrNotTransactional
+	}
+	if gg.tx, err = t.Begin(); err != nil {
+		return nil, err
+	}
+
+	return gg.tx, nil
+}
+
+// currentDb returns the Database in which records of the GoGetter are, without
+// beginning a transaction, which means nothing is created yet.
+func (gg *GoGetter) currentDb() Database {
+	if gg.getOptions().cleanupStrategy != CleanupByRollback {
+		return gg.getDb()
+	}
+
+	gg.txMutex.Lock()
+	defer gg.txMutex.Unlock()
+
+	if gg.tx == nil {
+		return nil
+	}
+
+	return gg.tx
+}
+
+// rollback rolls back the transaction of the GoGetter, if any, along with the
+// ones adopted from other GoGetters, and forgets all its dreams.
+func (gg *GoGetter) rollback() (err error) {
+	gg.txMutex.Lock()
+	txs := append(gg.adoptedTxs, gg.tx)
+	gg.tx = nil
+	gg.adoptedTxs = nil
+	gg.txMutex.Unlock()
+
+	gg.mutex.Lock()
+	gg.dreams = map[string][]Dream{}
+	gg.creations = map[string]int{}
+	gg.mutex.Unlock()
+
+	for i := len(txs) - 1; i >= 0; i-- {
+		if txs[i] == nil {
+			continue
+		}
+		if er := txs[i].Rollback(); er != nil && err == nil {
+			err = er
+		}
+	}
+
+	return
+}
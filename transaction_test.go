@@ -0,0 +1,132 @@
+package gogetter
+
+import (
+	"errors"
+	"fmt"
+	. "launchpad.net/gocheck"
+	"sync"
+)
+
+// fakeTxDb is a fakeDb supporting transactions, it records when transactions
+// are begun and rolled back, nested ones are named by their depth.
+type fakeTxDb struct {
+	*fakeDb
+	depth       int
+	events      *[]string
+	mutex       *sync.Mutex
+	rollbackErr error
+}
+
+func newFakeTxDb() *fakeTxDb {
+	return &fakeTxDb{fakeDb: newFakeDb(), events: &[]string{}, mutex: &sync.Mutex{}}
+}
+
+func (db *fakeTxDb) Begin() (Transaction, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	*db.events = append(*db.events, fmt.Sprintf("begin %d", db.depth+1))
+	return &fakeTxDb{fakeDb: newFakeDb(), depth: db.depth + 1, events: db.events, mutex: db.mutex, rollbackErr: db.rollbackErr}, nil
+}
+
+func (db *fakeTxDb) Rollback() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	*db.events = append(*db.events, fmt.Sprintf("rollback %d", db.depth))
+	return db.rollbackErr
+}
+
+func (s *GoGetterSuite) TestCleanupByRollback(c *C) {
+	db := newFakeTxDb()
+	gg := NewGoGetter(db)
+	gg.SetCleanupStrategy(CleanupByRollback)
+
+	_, err := gg.Grow("Member")
+	c.Check(err, Equals, nil)
+	c.Check(*db.events, HasLen, 0)
+
+	_, err = gg.Realize("Member")
+	c.Check(err, Equals, nil)
+	tx, err := gg.Db()
+	c.Check(err, Equals, nil)
+	c.Check(tx.(*fakeTxDb).created["members"], HasLen, 1)
+	c.Check(db.created["members"], HasLen, 0)
+
+	c.Check(gg.Apocalypse(), Equals, nil)
+	c.Check(*db.events, DeepEquals, []string{"begin 1", "rollback 1"})
+	c.Check(tx.(*fakeTxDb).removals, HasLen, 0)
+	c.Check(gg.Dreams("Member"), HasLen, 0)
+
+	// A new transaction is begun after rolling back
+	gg.Realize("Member")
+	c.Check(gg.Close(), Equals, nil)
+	c.Check(*db.events, DeepEquals, []string{"begin 1", "rollback 1", "begin 1", "rollback 1"})
+}
+
+func (s *GoGetterSuite) TestScopesRollBackSavepoints(c *C) {
+	db := newFakeTxDb()
+	gg := NewGoGetter(db)
+	gg.SetCleanupStrategy(CleanupByRollback)
+	gg.Realize("Member")
+	scope := gg.Scope()
+	scope.Realize("Account")
+	scope.Scope().Realize("Fixture")
+
+	c.Check(scope.Close(), Equals, nil)
+	c.Check(*db.events, DeepEquals, []string{"begin 1", "begin 2", "begin 3", "rollback 3", "rollback 2"})
+	c.Check(gg.Dreams("Member"), HasLen, 1)
+	c.Check(gg.Close(), Equals, nil)
+	c.Check((*db.events)[5], Equals, "rollback 1")
+}
+
+func (s *GoGetterSuite) TestRemoveByIdInTransaction(c *C) {
+	db := newFakeTxDb()
+	gg := NewGoGetter(db)
+	gg.SetCleanupStrategy(CleanupByRollback)
+	gg.Realize("Member")
+	c.Check(gg.Apocalypse("Member"), Equals, nil)
+	tx, _ := gg.Db()
+	c.Check(tx.(*fakeTxDb).removals, DeepEquals, []string{"members"})
+}
+
+func (s *GoGetterSuite) TestRollbackErrors(c *C) {
+	gg := NewGoGetter(newFakeDb())
+	gg.SetCleanupStrategy(CleanupByRollback)
+	_, err := gg.Realize("Member")
+	c.Check(err, Equals, ErrNotTransactional)
+
+	db := newFakeTxDb()
+	db.rollbackErr = errors.New("connection lost")
+	gg = NewGoGetter(db)
+	gg.SetCleanupStrategy(CleanupByRollback)
+	gg.Realize("Member")
+	err = gg.Close()
+	c.Check(err, ErrorMatches, "gogetter: failed to roll back: connection lost")
+	c.Check(errors.Is(err, db.rollbackErr), Equals, true)
+}
+
+func (s *GoGetterSuite) TestAdoptScopeWithRollback(c *C) {
+	db := newFakeTxDb()
+	gg := NewGoGetter(db)
+	gg.SetCleanupStrategy(CleanupByRollback)
+	scope := gg.Scope()
+	scope.Realize("Account")
+	scope.Scope().Realize("Fixture")
+	gg.Adopt(scope)
+
+	c.Check(scope.Close(), Equals, nil)
+	c.Check(*db.events, DeepEquals, []string{"begin 1", "begin 2", "begin 3"})
+	c.Check(gg.Dreams("Account"), HasLen, 1)
+	c.Check(gg.Dreams("Fixture"), HasLen, 1)
+	c.Check(gg.Close(), Equals, nil)
+	c.Check(*db.events, DeepEquals, []string{"begin 1", "begin 2", "begin 3", "rollback 1"})
+
+	// Transactions of other GoGetters are rolled back with the one adopting them
+	other := NewGoGetter(db)
+	other.SetCleanupStrategy(CleanupByRollback)
+	other.Realize("Member")
+	gg.Adopt(other)
+	c.Check(other.Close(), Equals, nil)
+	c.Check(gg.Dreams("Member"), HasLen, 1)
+	c.Check(gg.Close(), Equals, nil)
+	c.Check((*db.events)[4:], DeepEquals, []string{"begin 1", "rollback 1"})
+}
@@ -0,0 +1,52 @@
+package gogetter
+
+import (
+	"errors"
+	. "launchpad.net/gocheck"
+)
+
+// fakeTruncDb is a fakeDb supporting truncation.
+type fakeTruncDb struct {
+	*fakeDb
+	truncated [][]string
+	err       error
+}
+
+func (db *fakeTruncDb) Truncate(tables ...string) error {
+	db.truncated = append(db.truncated, tables)
+	return db.err
+}
+
+func (s *GoGetterSuite) TestCleanupByTruncation(c *C) {
+	db := &fakeTruncDb{fakeDb: newFakeDb()}
+	gg := NewGoGetter(db)
+	gg.SetCleanupStrategy(CleanupByTruncation)
+	gg.Realize("Author", Lesson{"Posts": SkipAssociation})
+	gg.Realize("Tag", Lesson{"PostId": 1})
+	gg.Realize("Guest Author", Lesson{"Posts": SkipAssociation})
+	gg.Realize("Member")
+
+	c.Check(gg.Apocalypse(), Equals, nil)
+	c.Check(db.truncated, DeepEquals, [][]string{{"members", "tags", "profiles", "authors"}})
+	c.Check(db.removals, HasLen, 0)
+	c.Check(gg.Dreams("Author"), HasLen, 0)
+	c.Check(gg.Dreams("Guest Author"), HasLen, 0)
+}
+
+func (s *GoGetterSuite) TestTruncationErrors(c *C) {
+	gg := NewGoGetter(newFakeDb())
+	gg.SetCleanupStrategy(CleanupByTruncation)
+	gg.Realize("Member")
+	err := gg.Apocalypse()
+	c.Check(errors.Is(err, ErrNotTruncater), Equals, true)
+	c.Check(gg.Dreams("Member"), HasLen, 1)
+
+	db := &fakeTruncDb{fakeDb: newFakeDb(), err: errors.New("locked")}
+	gg = NewGoGetter(db)
+	gg.SetCleanupStrategy(CleanupByTruncation)
+	gg.Realize("Member")
+	err = gg.Close()
+	c.Assert(err, FitsTypeOf, &ApocalypseError{})
+	c.Check(err.(*ApocalypseError).Errors[0].Table, Equals, "members")
+	c.Check(gg.Dreams("Member"), HasLen, 1)
+}